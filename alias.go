@@ -0,0 +1,62 @@
+package modem
+
+// Label the modem with the given IMEI, or the modem plugged into the given
+// USB port path (its sysfs name, e.g. 1-1.2), with alias. An empty alias
+// removes the label. An IMEI label wins over a port one. Modems already
+// present get an update event. Aliases set here last until the manager is
+// discarded, keep them in Config.Aliases or use WithStore to have them
+// across restarts.
+func (m *Manager) SetAlias(key string, alias string) {
+	m.mu.Lock()
+	aliases := make(map[string]string, len(m.cfg.Aliases)+1)
+	for k, v := range m.cfg.Aliases {
+		aliases[k] = v
+	}
+	if alias == "" {
+		delete(aliases, key)
+	} else {
+		aliases[key] = alias
+	}
+	m.cfg.Aliases = aliases
+	for _, d := range m.relabelLocked() {
+		m.publishLocked("update", d)
+	}
+	r, save := m.known[key]
+	if save {
+		r.Alias = alias
+		m.known[key] = r
+	}
+	m.mu.Unlock()
+	if save {
+		if err := m.db.Save(r); err != nil {
+			m.log.Warn("alias not saved", "imei", key, "err", err)
+		}
+	}
+}
+
+// relabelLocked updates the alias of every modem and returns the ready
+// ones whose alias changed. m.mu must be held.
+func (m *Manager) relabelLocked() []Modem {
+	var changed []Modem
+	for k, d := range m.devices {
+		if a := m.aliasLocked(d); a != d.Alias {
+			d.Alias = a
+			m.devices[k] = d
+			if d.State == StateReady {
+				changed = append(changed, d)
+			}
+		}
+	}
+	return changed
+}
+
+// aliasLocked returns the label of a modem. m.mu must be held.
+func (m *Manager) aliasLocked(d Modem) string {
+	if a, ok := m.cfg.Aliases[d.Imei]; ok && d.Imei != "" {
+		return a
+	}
+	if a, ok := m.cfg.Aliases[d.usb]; ok && d.usb != "" {
+		return a
+	}
+	return ""
+}
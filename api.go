@@ -0,0 +1,14 @@
+package modem
+
+// ManagerAPI is the part of Manager applications usually depend on.
+// Accept it instead of *Manager to substitute a mock in tests.
+type ManagerAPI interface {
+	AddFilter(vid string, pid string)
+	AddHandler(add func(Modem), update func(Modem), remove func(Modem))
+	List() map[string]Modem
+	Events() <-chan ModemEvent
+	Monitor() error
+	StopMonitor() error
+}
+
+var _ ManagerAPI = (*Manager)(nil)
@@ -0,0 +1,508 @@
+package modem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem/atparse"
+	"golang.org/x/sys/unix"
+)
+
+// Timeouts for commands sent by the manager.
+const (
+	commandTimeout = time.Second * 5
+	smsTimeout     = time.Second * 60
+)
+
+var ErrNoModem = errors.New("No ready modem with this IMEI")
+var ErrTimeout = errors.New("Timeout waiting for modem reply")
+
+// Errors of SendSMS and Connect for values that cannot be put into an AT
+// command: a quote, backslash, ";" or control character in a number or
+// APN would end the string parameter, and Ctrl-Z or ESC in a text would
+// end the message, letting the rest of the value run as another command.
+var ErrInvalidNumber = errors.New("Phone number not allowed in an AT command")
+var ErrInvalidAPN = errors.New("APN not allowed in an AT command")
+var ErrInvalidText = errors.New("SMS text holds Ctrl-Z or ESC")
+
+// CommandError is the error result code an AT command ended with.
+type CommandError struct {
+	Cmd    string
+	Result string // ERROR, +CME ERROR: <err>, NO CARRIER, ...
+}
+
+func (e *CommandError) Error() string {
+	return e.Cmd + ": " + e.Result
+}
+
+// finalResult reports whether line ends a command reply, and how.
+func finalResult(cmd, line string) (bool, error) {
+	switch line {
+	case "OK", "CONNECT":
+		return true, nil
+	case "ERROR", "NO CARRIER", "NO DIALTONE", "BUSY", "NO ANSWER":
+		return true, &CommandError{Cmd: cmd, Result: line}
+	}
+	if strings.HasPrefix(line, "+CME ERROR:") || strings.HasPrefix(line, "+CMS ERROR:") {
+		return true, &CommandError{Cmd: cmd, Result: line}
+	}
+	if strings.HasPrefix(line, "CONNECT ") {
+		return true, nil
+	}
+	return false, nil
+}
+
+// quote returns s as a string parameter of an AT command, or false if s
+// holds a character that would end the parameter or the command. AT
+// strings have no escapes for them, and some modems read a backslash as
+// the start of a hex escape, so they are refused rather than escaped.
+func quote(s string) (string, bool) {
+	for _, r := range s {
+		if r == '"' || r == '\\' || r == ';' || r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+	return `"` + s + `"`, true
+}
+
+// Commands whose arguments carry a SIM PIN, PUK or password.
+var secretCommands = []string{"AT+CPIN", "AT+CLCK", "AT+CPWD"}
+
+// redact hides the arguments of commands carrying secrets, for hooks,
+// errors, logs and capture files: AT+CPIN="1234" becomes AT+CPIN=***.
+// Queries such as AT+CPIN? and AT+CLCK=? are kept.
+func redact(cmd string) string {
+	for _, c := range secretCommands {
+		if len(cmd) > len(c) && strings.EqualFold(cmd[:len(c)], c) && cmd[len(c)] == '=' && cmd[len(c):] != "=?" {
+			return cmd[:len(c)] + "=***"
+		}
+	}
+	return cmd
+}
+
+// modemByImei finds the ready modem with the given IMEI.
+func (m *Manager) modemByImei(imei string) (Modem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.devices {
+		if d.State == StateReady && d.Imei == imei {
+			return d, nil
+		}
+	}
+	return Modem{}, ErrNoModem
+}
+
+// portLock returns the mutex serializing access to a port.
+func (m *Manager) portLock(node string) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.portLocks == nil {
+		m.portLocks = make(map[string]*sync.Mutex)
+	}
+	l, ok := m.portLocks[node]
+	if !ok {
+		l = &sync.Mutex{}
+		m.portLocks[node] = l
+	}
+	return l
+}
+
+// atPort is an AT command port opened exclusively for this process.
+type atPort struct {
+	m     *Manager
+	ctx   context.Context
+	node  string
+	imei  string
+	rw    io.ReadWriteCloser
+	lock  *sync.Mutex
+	lines *lineReader
+
+	reopening bool // failures are returned, not reopened
+}
+
+// openAT waits for other users of the port in this process, then opens it.
+// Commands sent on the port are attributed to ctx and imei in hooks.
+// A port held by OpenPort is not waited for.
+func (m *Manager) openAT(ctx context.Context, node string, imei string) (*atPort, error) {
+	if m.isHeld(node) {
+		return nil, ErrPortHeld
+	}
+	l := m.portLock(node)
+	l.Lock()
+	rw, err := m.openPort(node)
+	if err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return newATPort(m, ctx, node, imei, rw, l), nil
+}
+
+func newATPort(m *Manager, ctx context.Context, node string, imei string, rw io.ReadWriteCloser, l *sync.Mutex) *atPort {
+	return &atPort{m: m, ctx: ctx, node: node, imei: imei, rw: rw, lock: l, lines: newLineReader(rw)}
+}
+
+func (p *atPort) Close() error {
+	err := p.rw.Close()
+	p.lock.Unlock()
+	return err
+}
+
+// Command sends cmd and returns the information lines of its reply,
+// without echo and final result code.
+func (p *atPort) Command(cmd string, timeout time.Duration) (string, error) {
+	return p.send(cmd, cmd+"\r", timeout, false)
+}
+
+// send writes data and reads the reply to cmd. With prompt set it returns
+// as soon as the modem asks for more input with "> ".
+func (p *atPort) send(cmd string, data string, timeout time.Duration, prompt bool) (resp string, err error) {
+	start := p.m.clock.Now()
+	shown := redact(cmd)
+	done := p.m.hooks.commandStart(p.ctx, CommandInfo{Port: p.node, Imei: p.imei, Cmd: shown})
+	defer func() {
+		done(err)
+		p.m.hooks.command(p.node, shown, err, p.m.clock.Now().Sub(start))
+	}()
+
+	resp, err = p.exchange(cmd, data, timeout, prompt)
+	if gone(err) && !p.reopening && p.reopen(err) == nil {
+		resp, err = p.exchange(cmd, data, timeout, prompt)
+	}
+	return resp, err
+}
+
+func (p *atPort) exchange(cmd string, data string, timeout time.Duration, prompt bool) (string, error) {
+	shown := redact(cmd)
+	if shown != cmd {
+		p.m.hideCapture(p.node, shown)
+		defer p.m.hideCapture(p.node, "")
+	}
+	p.lines.Expect(cmd)
+	if _, err := p.rw.Write([]byte(data)); err != nil {
+		return "", err
+	}
+	lines, err := p.readReply(shown, timeout, prompt)
+	return strings.Join(lines, "\n"), err
+}
+
+// gone reports whether err means the port stopped working, as it does
+// when the modem resets and its device nodes are recreated.
+func gone(err error) bool {
+	return errors.Is(err, unix.EIO) || errors.Is(err, unix.ENODEV) || errors.Is(err, unix.ENXIO)
+}
+
+// How long a failed command port is waited for to come back.
+const ReopenTimeout = time.Second * 30
+
+// reopen waits for the port's device node to come back, opens it again
+// and replays the setup sequence. On success it emits EventReopen for the
+// modem using the port.
+func (p *atPort) reopen(cause error) error {
+	m := p.m
+	m.log.Warn("port failed, reopening", "tty", p.node, "err", cause)
+	p.rw.Close()
+	m.mu.Lock()
+	delete(m.bauds, p.node)
+	m.mu.Unlock()
+
+	deadline := m.clock.Now().Add(ReopenTimeout)
+	for {
+		rw, err := m.openPort(p.node)
+		if err == nil {
+			p.rw = rw
+			p.lines = newLineReader(rw)
+			break
+		}
+		if p.ctx.Err() != nil || !m.clock.Now().Before(deadline) {
+			// Leave a port that fails every operation for Close.
+			p.rw = brokenPort{cause}
+			m.log.Error("port did not come back", "tty", p.node, "err", err)
+			return err
+		}
+		m.clock.Sleep(time.Millisecond * 500)
+	}
+	p.reopening = true
+	m.prepare(p)
+	p.reopening = false
+	m.log.Info("port reopened", "tty", p.node)
+	if d, err := m.modemByImei(p.imei); err == nil {
+		m.emit(ModemEvent{Type: EventReopen, Modem: d})
+	}
+	return nil
+}
+
+type brokenPort struct{ err error }
+
+func (b brokenPort) Read([]byte) (int, error)  { return 0, b.err }
+func (b brokenPort) Write([]byte) (int, error) { return 0, b.err }
+func (b brokenPort) Close() error              { return nil }
+
+// deadliner is a port whose reads can be given a deadline.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// How long a read waits for the modem before the reply's timeout is
+// checked. Timeouts run on the manager's clock, reads wait in real time.
+const readSlice = time.Millisecond * 100
+
+// readReply accumulates what the modem sends until the final result code,
+// the prompt, the timeout or the cancellation of the port's context. The
+// timeout runs on the manager's clock from the end of the first read and
+// is checked between reads, so a fake clock still sees what the modem
+// sent. cmd names the command in errors and must be redacted.
+func (p *atPort) readReply(cmd string, timeout time.Duration, prompt bool) ([]string, error) {
+	var expired <-chan time.Time
+	dl, _ := p.rw.(deadliner)
+	if dl != nil {
+		// Interrupt a pending read when the context is done.
+		defer context.AfterFunc(p.ctx, func() { dl.SetReadDeadline(time.Unix(1, 0)) })()
+	}
+	var lines []string
+	read := false
+	for {
+		for {
+			line, ok := p.lines.Next()
+			if !ok {
+				break
+			}
+			if line == "" {
+				continue
+			}
+			if s, ok := parseJamming(line); ok {
+				if p.imei != "" {
+					p.m.jamming(p.imei, s)
+				}
+				continue
+			}
+			if done, err := finalResult(cmd, line); done {
+				return lines, err
+			}
+			lines = append(lines, line)
+		}
+		if prompt && p.lines.Prompt() {
+			return lines, nil
+		}
+		if expired != nil {
+			select {
+			case <-expired:
+				return lines, ErrTimeout
+			default:
+			}
+		} else if read {
+			expired = p.m.clock.After(timeout)
+		}
+		if dl != nil {
+			dl.SetReadDeadline(time.Now().Add(readSlice))
+		}
+		if err := p.ctx.Err(); err != nil {
+			return lines, err
+		}
+		switch err := p.lines.Fill(); {
+		case err == nil:
+		case errors.Is(err, os.ErrDeadlineExceeded):
+		case err == io.EOF && dl == nil:
+			// Ports without deadlines return io.EOF when their own read
+			// timeout expires.
+		default:
+			return lines, err
+		}
+		read = true
+	}
+}
+
+// Send an AT command to the modem with the given IMEI and return the
+// information lines of the reply. An error result is a *CommandError.
+// Commands holding control characters fail with ErrUnsafeCommand, and
+// those refused by Config.Commands with ErrCommandDenied.
+func (m *Manager) SendAT(imei string, cmd string) (string, error) {
+	return m.SendATContext(context.Background(), imei, cmd)
+}
+
+// SendAT with a context, which cancels the wait for the reply and is
+// passed to the OnCommandStart hook.
+func (m *Manager) SendATContext(ctx context.Context, imei string, cmd string) (string, error) {
+	if err := m.allowed(cmd); err != nil {
+		return "", err
+	}
+	return m.command(ctx, imei, cmd)
+}
+
+// command sends an AT command of the manager itself, which the command
+// policy does not apply to.
+func (m *Manager) command(ctx context.Context, imei string, cmd string) (string, error) {
+	d, err := m.modemByImei(imei)
+	if err != nil {
+		return "", err
+	}
+	if po, ok := m.backend.(PortOwner); ok {
+		return po.Command(d, cmd, commandTimeout)
+	}
+	p, err := m.openAT(ctx, d.Tty, d.Imei)
+	if err != nil {
+		return "", err
+	}
+	defer p.Close()
+	return p.Command(cmd, commandTimeout)
+}
+
+// Send a text SMS from the modem with the given IMEI. Numbers and texts
+// that cannot be sent in AT commands fail with ErrInvalidNumber and
+// ErrInvalidText.
+func (m *Manager) SendSMS(imei string, number string, text string) error {
+	return m.SendSMSContext(context.Background(), imei, number, text)
+}
+
+// SendSMS with a context, see SendATContext.
+func (m *Manager) SendSMSContext(ctx context.Context, imei string, number string, text string) error {
+	qnumber, ok := quote(number)
+	if !ok {
+		return ErrInvalidNumber
+	}
+	if strings.ContainsAny(text, "\x1a\x1b") {
+		return ErrInvalidText
+	}
+	cmd := "AT+CMGS=" + qnumber
+	if err := m.allowed("AT+CMGF=1", cmd); err != nil {
+		return err
+	}
+	d, err := m.modemByImei(imei)
+	if err != nil {
+		return err
+	}
+	if po, ok := m.backend.(PortOwner); ok {
+		return po.SendSMS(d, number, text)
+	}
+	p, err := m.openAT(ctx, d.Tty, d.Imei)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if _, err := p.Command("AT+CMGF=1", commandTimeout); err != nil {
+		return err
+	}
+	if _, err := p.send(cmd, cmd+"\r", commandTimeout, true); err != nil {
+		return err
+	}
+	_, err = p.send(cmd, text+"\x1a", smsTimeout, false)
+	return err
+}
+
+// Activate a packet data connection on the modem with the given IMEI,
+// using PDP context 1. The modem's network interface carries the traffic.
+// An empty apn selects the configured one for the modem's SIM.
+// An APN that cannot be sent in an AT command fails with ErrInvalidAPN.
+func (m *Manager) Connect(imei string, apn string) error {
+	return m.ConnectContext(context.Background(), imei, apn)
+}
+
+// Connect with a context, see SendATContext.
+func (m *Manager) ConnectContext(ctx context.Context, imei string, apn string) error {
+	d, err := m.modemByImei(imei)
+	if err != nil {
+		return err
+	}
+	if apn == "" {
+		apn = m.apnFor(d.Iccid)
+	}
+	qapn, ok := quote(apn)
+	if !ok {
+		return ErrInvalidAPN
+	}
+	if err := m.allowed(`AT+CGDCONT=1,"IP",`+qapn, "AT+CGACT=1,1"); err != nil {
+		return err
+	}
+	if po, ok := m.backend.(PortOwner); ok {
+		err = po.Connect(d, apn)
+	} else {
+		err = m.activate(ctx, d, qapn)
+	}
+	if err != nil {
+		return err
+	}
+	m.emit(ModemEvent{Type: EventConnect, Modem: m.connected(d, true)})
+	return nil
+}
+
+// activate sets up and activates PDP context 1 with the quoted APN.
+func (m *Manager) activate(ctx context.Context, d Modem, qapn string) error {
+	p, err := m.openAT(ctx, d.Tty, d.Imei)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+	if _, err := p.Command(`AT+CGDCONT=1,"IP",`+qapn, commandTimeout); err != nil {
+		return err
+	}
+	_, err = p.Command("AT+CGACT=1,1", commandTimeout*6)
+	return err
+}
+
+// Deactivate the packet data connection started by Connect.
+func (m *Manager) Disconnect(imei string) error {
+	if err := m.allowed("AT+CGACT=0,1"); err != nil {
+		return err
+	}
+	d, err := m.modemByImei(imei)
+	if err != nil {
+		return err
+	}
+	if po, ok := m.backend.(PortOwner); ok {
+		err = po.Disconnect(d)
+	} else {
+		_, err = m.command(context.Background(), imei, "AT+CGACT=0,1")
+	}
+	if err != nil {
+		return err
+	}
+	m.emit(ModemEvent{Type: EventDisconnect, Modem: m.connected(d, false)})
+	return nil
+}
+
+// connected records whether d has a data connection and returns it.
+func (m *Manager) connected(d Modem, on bool) Modem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d.Connected = on
+	for k, cur := range m.devices {
+		if cur.State == StateReady && cur.Imei == d.Imei {
+			cur.Connected = on
+			m.devices[k] = cur
+			d = cur
+		}
+	}
+	return d
+}
+
+// Read the signal quality of the modem with the given IMEI.
+func (m *Manager) Signal(imei string) (atparse.Signal, error) {
+	return m.SignalContext(context.Background(), imei)
+}
+
+// Signal with a context, see SendATContext.
+func (m *Manager) SignalContext(ctx context.Context, imei string) (atparse.Signal, error) {
+	resp, err := m.command(ctx, imei, "AT+CSQ")
+	if err != nil {
+		return atparse.Signal{}, err
+	}
+	return atparse.ParseCSQ(resp)
+}
+
+// Read the network registration of the modem with the given IMEI.
+func (m *Manager) Registration(imei string) (atparse.Registration, error) {
+	return m.RegistrationContext(context.Background(), imei)
+}
+
+// Registration with a context, see SendATContext.
+func (m *Manager) RegistrationContext(ctx context.Context, imei string) (atparse.Registration, error) {
+	resp, err := m.command(ctx, imei, "AT+CREG?")
+	if err != nil {
+		return atparse.Registration{}, err
+	}
+	return atparse.ParseCREG(resp)
+}
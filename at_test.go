@@ -0,0 +1,124 @@
+package modem
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"+46701234567", `"+46701234567"`, true},
+		{"internet.example", `"internet.example"`, true},
+		{"", `""`, true},
+		{`+4670";AT+CFUN=0`, "", false},
+		{"+4670;+CFUN=0", "", false},
+		{"+4670\rAT+CFUN=0", "", false},
+		{"+4670\x1a", "", false},
+		{`apn\22`, "", false},
+		{"apn\x7f", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := quote(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("quote(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSendSMSRejectsInjection(t *testing.T) {
+	m := New()
+	tests := []struct {
+		number string
+		text   string
+		want   error
+	}{
+		{`+4670"`, "hi", ErrInvalidNumber},
+		{"+4670;+CFUN=0", "hi", ErrInvalidNumber},
+		{"+4670\r", "hi", ErrInvalidNumber},
+		{"+4670", "hi\x1aAT+CFUN=0\r", ErrInvalidText},
+		{"+4670", "hi\x1b", ErrInvalidText},
+		{"+4670", `a "quoted"; text`, ErrNoModem},
+	}
+	for _, tt := range tests {
+		if err := m.SendSMS("490154203237518", tt.number, tt.text); !errors.Is(err, tt.want) {
+			t.Errorf("SendSMS(%q, %q) = %v, want %v", tt.number, tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`AT+CPIN="1234"`, "AT+CPIN=***"},
+		{`at+cpin="12345678","1234"`, "at+cpin=***"},
+		{`AT+CLCK="SC",0,"1234"`, "AT+CLCK=***"},
+		{`AT+CPWD="SC","1234","4321"`, "AT+CPWD=***"},
+		{"AT+CPIN?", "AT+CPIN?"},
+		{"AT+CLCK=?", "AT+CLCK=?"},
+		{"AT+CPINR", "AT+CPINR"},
+		{"AT+CSQ", "AT+CSQ"},
+	}
+	for _, tt := range tests {
+		if got := redact(tt.in); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// ownerBackend is a chanBackend owning the ports of its modems.
+type ownerBackend struct{ chanBackend }
+
+func (ownerBackend) Command(Modem, string, time.Duration) (string, error) { return "", nil }
+func (ownerBackend) SendSMS(Modem, string, string) error                  { return nil }
+func (ownerBackend) Connect(Modem, string) error                          { return nil }
+func (ownerBackend) Disconnect(Modem) error                               { return nil }
+
+func TestConnected(t *testing.T) {
+	m := New()
+	devs := make(chanBackend)
+	m.backend = ownerBackend{devs}
+	m.AddFilter("12d1", "1001")
+	events := m.Events()
+	if err := m.Monitor(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.StopMonitor() })
+	usb1, tty1 := usbModem("1", replugImei)
+	_, tty2 := usbModem("2", replugImei)
+	devs <- tty1
+	next(t, events)
+
+	connected := func(want bool) {
+		t.Helper()
+		if d := m.List()[usb1.node]; d.Connected != want {
+			t.Errorf("List() has Connected %v, want %v", d.Connected, want)
+		}
+	}
+	if err := m.Connect(replugImei, "internet"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(t, events); ev.Type != EventConnect || !ev.Modem.Connected {
+		t.Errorf("Connect emitted %s with Connected %v", ev.Type, ev.Modem.Connected)
+	}
+	connected(true)
+	if err := m.Disconnect(replugImei); err != nil {
+		t.Fatal(err)
+	}
+	if ev := next(t, events); ev.Type != EventDisconnect || ev.Modem.Connected {
+		t.Errorf("Disconnect emitted %s with Connected %v", ev.Type, ev.Modem.Connected)
+	}
+	connected(false)
+
+	m.Connect(replugImei, "internet")
+	next(t, events)
+	devs <- unplug(usb1)
+	next(t, events)
+	devs <- tty2
+	if ev := next(t, events); ev.Modem.Connected {
+		t.Error("replugged modem is still connected")
+	}
+}
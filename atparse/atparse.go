@@ -0,0 +1,386 @@
+/*
+Package atparse parses the replies modems give to common AT commands.
+
+Every parser takes the raw response text as read from the port, with or
+without the echoed command and the final result code, and returns an error
+instead of panicking when the firmware sends something unexpected.
+
+	s, err := atparse.ParseCSQ("+CSQ: 17,99\r\n\r\nOK\r\n")
+	if err == nil {
+		fmt.Println(s.DBm())
+	}
+*/
+package atparse
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformed is wrapped by every parse error.
+var ErrMalformed = errors.New("Malformed response")
+
+// ErrNoData is returned when the response holds no line for the command.
+var ErrNoData = errors.New("No data in response")
+
+func malformed(prefix, line string) error {
+	return fmt.Errorf("%s %q: %w", prefix, line, ErrMalformed)
+}
+
+// Lines splits a response into its non-empty lines, accepting any mix of
+// \r and \n as separators.
+func Lines(resp string) []string {
+	return strings.FieldsFunc(resp, func(r rune) bool { return r == '\r' || r == '\n' })
+}
+
+// Fields splits the parameter list of an information response on commas.
+// Commas inside double quotes do not split, and the quotes are removed.
+func Fields(s string) []string {
+	var fields []string
+	var b strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			fields = append(fields, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(fields, strings.TrimSpace(b.String()))
+}
+
+// params returns the parameter part of every line starting with prefix,
+// e.g. "17,99" for "+CSQ: 17,99".
+func params(resp, prefix string) []string {
+	var out []string
+	for _, l := range Lines(resp) {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(l), prefix+":"); ok {
+			out = append(out, strings.TrimSpace(rest))
+		}
+	}
+	return out
+}
+
+// first returns the parameters of the first line starting with prefix.
+func first(resp, prefix string) (string, error) {
+	p := params(resp, prefix)
+	if len(p) == 0 {
+		return "", fmt.Errorf("%s: %w", prefix, ErrNoData)
+	}
+	return p[0], nil
+}
+
+// quoted reports whether field i of s starts with a double quote. The
+// fields before it must not hold quoted commas.
+func quoted(s string, i int) bool {
+	f := strings.SplitN(s, ",", i+2)
+	return len(f) > i && strings.HasPrefix(strings.TrimSpace(f[i]), `"`)
+}
+
+// atoi parses an optional integer field, returning def when it is empty.
+func atoi(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// Signal is the reply to AT+CSQ.
+type Signal struct {
+	RSSI int // 0-31, 99 when unknown
+	BER  int // 0-7, 99 when unknown
+}
+
+// Known reports whether the modem had a signal reading.
+func (s Signal) Known() bool {
+	return s.RSSI >= 0 && s.RSSI <= 31
+}
+
+// DBm converts the RSSI index to dBm, returning 0 when it is unknown.
+func (s Signal) DBm() int {
+	if !s.Known() {
+		return 0
+	}
+	return -113 + 2*s.RSSI
+}
+
+// ParseCSQ parses "+CSQ: <rssi>,<ber>".
+func ParseCSQ(resp string) (Signal, error) {
+	p, err := first(resp, "+CSQ")
+	if err != nil {
+		return Signal{}, err
+	}
+	f := Fields(p)
+	if len(f) != 2 {
+		return Signal{}, malformed("+CSQ", p)
+	}
+	rssi, err1 := strconv.Atoi(f[0])
+	ber, err2 := strconv.Atoi(f[1])
+	if err1 != nil || err2 != nil {
+		return Signal{}, malformed("+CSQ", p)
+	}
+	return Signal{RSSI: rssi, BER: ber}, nil
+}
+
+// Operator is the reply to AT+COPS?.
+type Operator struct {
+	Mode   int
+	Format int    // -1 when not registered
+	Name   string // long, short or numeric name depending on Format
+	Act    int    // access technology, -1 when not reported
+}
+
+// ParseCOPS parses "+COPS: <mode>[,<format>,<oper>[,<AcT>]]".
+func ParseCOPS(resp string) (Operator, error) {
+	p, err := first(resp, "+COPS")
+	if err != nil {
+		return Operator{}, err
+	}
+	f := Fields(p)
+	o := Operator{Format: -1, Act: -1}
+	if o.Mode, err = strconv.Atoi(f[0]); err != nil {
+		return Operator{}, malformed("+COPS", p)
+	}
+	switch len(f) {
+	case 1:
+	case 3, 4:
+		if o.Format, err = strconv.Atoi(f[1]); err != nil {
+			return Operator{}, malformed("+COPS", p)
+		}
+		o.Name = f[2]
+		if len(f) == 4 {
+			if o.Act, err = atoi(f[3], -1); err != nil {
+				return Operator{}, malformed("+COPS", p)
+			}
+		}
+	default:
+		return Operator{}, malformed("+COPS", p)
+	}
+	return o, nil
+}
+
+// Registration states reported by +CREG, +CGREG and +CEREG.
+const (
+	NotRegistered = iota
+	RegisteredHome
+	Searching
+	Denied
+	UnknownState
+	RegisteredRoaming
+)
+
+// Registration is the reply to AT+CREG?, AT+CGREG? or AT+CEREG?.
+type Registration struct {
+	N    int // unsolicited result mode, -1 for unsolicited lines
+	Stat int
+	LAC  string // location or tracking area code, hex
+	CI   string // cell id, hex
+	Act  int    // access technology, -1 when not reported
+}
+
+// Registered reports whether the modem is registered at home or roaming.
+func (r Registration) Registered() bool {
+	return r.Stat == RegisteredHome || r.Stat == RegisteredRoaming
+}
+
+// ParseCREG parses the solicited "+CREG: <n>,<stat>[,<lac>,<ci>[,<AcT>]]"
+// form as well as the unsolicited "+CREG: <stat>[,<lac>,<ci>[,<AcT>]]".
+// The +CGREG and +CEREG variants are accepted too.
+func ParseCREG(resp string) (Registration, error) {
+	var p, prefix string
+	for _, pre := range []string{"+CREG", "+CGREG", "+CEREG"} {
+		if v, err := first(resp, pre); err == nil {
+			p, prefix = v, pre
+			break
+		}
+	}
+	if prefix == "" {
+		return Registration{}, fmt.Errorf("+CREG: %w", ErrNoData)
+	}
+	f := Fields(p)
+	r := Registration{N: -1, Act: -1}
+	// The solicited form starts with the mode: n,stat, n,stat,lac,ci or
+	// n,stat,lac,ci,AcT. Four fields are also the unsolicited
+	// stat,lac,ci,AcT, which has the quoted lac second.
+	if len(f) == 2 || len(f) == 5 || len(f) == 4 && !quoted(p, 1) {
+		n, err := strconv.Atoi(f[0])
+		if err != nil {
+			return Registration{}, malformed(prefix, p)
+		}
+		r.N = n
+		f = f[1:]
+	}
+	stat, err := strconv.Atoi(f[0])
+	if err != nil {
+		return Registration{}, malformed(prefix, p)
+	}
+	r.Stat = stat
+	switch len(f) {
+	case 1:
+	case 3, 4:
+		r.LAC, r.CI = f[1], f[2]
+		if len(f) == 4 {
+			if r.Act, err = atoi(f[3], -1); err != nil {
+				return Registration{}, malformed(prefix, p)
+			}
+		}
+	default:
+		return Registration{}, malformed(prefix, p)
+	}
+	return r, nil
+}
+
+// Message is one entry of a text mode AT+CMGL listing.
+type Message struct {
+	Index  int
+	Status string // REC UNREAD, REC READ, STO UNSENT or STO SENT
+	Sender string // originating address for received messages
+	Alpha  string
+	Time   string // service centre time stamp, as sent by the modem
+	Text   string
+}
+
+// ParseCMGL parses a text mode (AT+CMGF=1) message listing:
+//
+//	+CMGL: <index>,<stat>,<oa/da>,[<alpha>],[<scts>]
+//	<data>
+//
+// Message text may span several lines and runs until the next +CMGL
+// header or the final result code.
+func ParseCMGL(resp string) ([]Message, error) {
+	var msgs []Message
+	var text []string
+	flush := func() {
+		if len(msgs) > 0 {
+			msgs[len(msgs)-1].Text = strings.Join(text, "\n")
+		}
+		text = text[:0]
+	}
+	for _, l := range Lines(resp) {
+		t := strings.TrimSpace(l)
+		if rest, ok := strings.CutPrefix(t, "+CMGL:"); ok {
+			flush()
+			p := strings.TrimSpace(rest)
+			f := Fields(p)
+			if len(f) < 3 {
+				return nil, malformed("+CMGL", p)
+			}
+			idx, err := strconv.Atoi(f[0])
+			if err != nil {
+				return nil, malformed("+CMGL", p)
+			}
+			msg := Message{Index: idx, Status: f[1], Sender: f[2]}
+			if len(f) > 3 {
+				msg.Alpha = f[3]
+			}
+			if len(f) > 4 {
+				msg.Time = f[4]
+			}
+			msgs = append(msgs, msg)
+			continue
+		}
+		if t == "OK" || t == "AT+CMGL" || strings.HasPrefix(t, "AT+CMGL=") {
+			continue
+		}
+		if len(msgs) > 0 {
+			text = append(text, l)
+		}
+	}
+	flush()
+	return msgs, nil
+}
+
+// PDPContext is one entry of an AT+CGDCONT? listing.
+type PDPContext struct {
+	CID  int
+	Type string // IP, IPV6, IPV4V6, ...
+	APN  string
+	Addr string
+}
+
+// ParseCGDCONT parses "+CGDCONT: <cid>,<PDP_type>,<APN>[,<PDP_addr>,...]"
+// lines.
+func ParseCGDCONT(resp string) ([]PDPContext, error) {
+	var ctxs []PDPContext
+	for _, p := range params(resp, "+CGDCONT") {
+		f := Fields(p)
+		if len(f) < 3 {
+			return nil, malformed("+CGDCONT", p)
+		}
+		cid, err := strconv.Atoi(f[0])
+		if err != nil {
+			return nil, malformed("+CGDCONT", p)
+		}
+		c := PDPContext{CID: cid, Type: f[1], APN: f[2]}
+		if len(f) > 3 {
+			c.Addr = f[3]
+		}
+		ctxs = append(ctxs, c)
+	}
+	return ctxs, nil
+}
+
+// SIMResponse is the reply to AT+CRSM.
+type SIMResponse struct {
+	SW1  int
+	SW2  int
+	Data []byte // response data, decoded from hex
+}
+
+// OK reports whether the SIM completed the command, status 90 00 or 91 xx.
+func (r SIMResponse) OK() bool {
+	return r.SW1 == 0x90 && r.SW2 == 0 || r.SW1 == 0x91
+}
+
+// ParseCRSM parses "+CRSM: <sw1>,<sw2>[,<response>]".
+func ParseCRSM(resp string) (SIMResponse, error) {
+	p, err := first(resp, "+CRSM")
+	if err != nil {
+		return SIMResponse{}, err
+	}
+	f := Fields(p)
+	if len(f) < 2 {
+		return SIMResponse{}, malformed("+CRSM", p)
+	}
+	sw1, err1 := strconv.Atoi(f[0])
+	sw2, err2 := strconv.Atoi(f[1])
+	if err1 != nil || err2 != nil {
+		return SIMResponse{}, malformed("+CRSM", p)
+	}
+	r := SIMResponse{SW1: sw1, SW2: sw2}
+	if len(f) > 2 && f[2] != "" {
+		if r.Data, err = hex.DecodeString(f[2]); err != nil {
+			return SIMResponse{}, malformed("+CRSM", p)
+		}
+	}
+	return r, nil
+}
+
+// ParsePLMNs decodes a list of networks coded as in 3GPP TS 24.008, such
+// as the SIM's EF_FPLMN, into MCC and MNC digits, e.g. "24001". Unused
+// entries are skipped.
+func ParsePLMNs(data []byte) []string {
+	var plmns []string
+	for i := 0; i+3 <= len(data); i += 3 {
+		b := data[i : i+3]
+		if b[0] == 0xff && b[1] == 0xff && b[2] == 0xff {
+			continue
+		}
+		digits := []byte{b[0] & 0xf, b[0] >> 4, b[1] & 0xf, b[2] & 0xf, b[2] >> 4, b[1] >> 4}
+		var s strings.Builder
+		for _, d := range digits {
+			if d <= 9 {
+				s.WriteByte('0' + d)
+			}
+		}
+		plmns = append(plmns, s.String())
+	}
+	return plmns
+}
@@ -0,0 +1,307 @@
+package atparse
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"17,99", []string{"17", "99"}},
+		{`0,2,"Tele2, SE",7`, []string{"0", "2", "Tele2, SE", "7"}},
+		{` 1 , "a" `, []string{"1", "a"}},
+		{"", []string{""}},
+		{"1,,3", []string{"1", "", "3"}},
+	}
+	for _, tt := range tests {
+		if got := Fields(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Fields(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCSQ(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Signal
+		err  error
+	}{
+		{"+CSQ: 17,99\r\n\r\nOK\r\n", Signal{RSSI: 17, BER: 99}, nil},
+		{"AT+CSQ\r\r\n+CSQ: 31,0\r\nOK", Signal{RSSI: 31, BER: 0}, nil},
+		{"+CSQ: 99,99", Signal{RSSI: 99, BER: 99}, nil},
+		{"+CSQ: 17", Signal{}, ErrMalformed},
+		{"+CSQ: a,b", Signal{}, ErrMalformed},
+		{"OK", Signal{}, ErrNoData},
+	}
+	for _, tt := range tests {
+		got, err := ParseCSQ(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCSQ(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestSignalDBm(t *testing.T) {
+	tests := []struct {
+		rssi  int
+		known bool
+		dbm   int
+	}{
+		{0, true, -113},
+		{17, true, -79},
+		{31, true, -51},
+		{99, false, 0},
+		{-1, false, 0},
+	}
+	for _, tt := range tests {
+		s := Signal{RSSI: tt.rssi}
+		if s.Known() != tt.known || s.DBm() != tt.dbm {
+			t.Errorf("RSSI %d: Known() = %v, DBm() = %d, want %v, %d", tt.rssi, s.Known(), s.DBm(), tt.known, tt.dbm)
+		}
+	}
+}
+
+func TestParseCOPS(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Operator
+		err  error
+	}{
+		{`+COPS: 0,0,"Tele2 SE",7`, Operator{Mode: 0, Format: 0, Name: "Tele2 SE", Act: 7}, nil},
+		{`+COPS: 1,2,"24007"`, Operator{Mode: 1, Format: 2, Name: "24007", Act: -1}, nil},
+		{"+COPS: 0", Operator{Mode: 0, Format: -1, Act: -1}, nil},
+		{`+COPS: 0,0,"A",`, Operator{Mode: 0, Format: 0, Name: "A", Act: -1}, nil},
+		{"+COPS: 0,0", Operator{}, ErrMalformed},
+		{`+COPS: x,0,"A"`, Operator{}, ErrMalformed},
+		{`+COPS: 0,0,"A",x`, Operator{}, ErrMalformed},
+		{"+COPS:", Operator{}, ErrMalformed},
+		{"ERROR", Operator{}, ErrNoData},
+	}
+	for _, tt := range tests {
+		got, err := ParseCOPS(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCOPS(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseCREG(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Registration
+		err  error
+	}{
+		// solicited
+		{"+CREG: 0,1\r\nOK", Registration{N: 0, Stat: 1, Act: -1}, nil},
+		{`+CREG: 2,5,"1A2B","01C3D4E5"`, Registration{N: 2, Stat: 5, LAC: "1A2B", CI: "01C3D4E5", Act: -1}, nil},
+		{`+CREG: 2,1,"1A2B","01C3D4E5",7`, Registration{N: 2, Stat: 1, LAC: "1A2B", CI: "01C3D4E5", Act: 7}, nil},
+		{`+CGREG: 0,2`, Registration{N: 0, Stat: 2, Act: -1}, nil},
+		{`+CEREG: 2,1,"00C3","0A1B2C3D",7`, Registration{N: 2, Stat: 1, LAC: "00C3", CI: "0A1B2C3D", Act: 7}, nil},
+		// unsolicited
+		{"+CREG: 3", Registration{N: -1, Stat: 3, Act: -1}, nil},
+		{`+CREG: 1,"1A2B","01C3D4E5"`, Registration{N: -1, Stat: 1, LAC: "1A2B", CI: "01C3D4E5", Act: -1}, nil},
+		{`+CREG: 1,"1A2B","01C3D4E5",7`, Registration{N: -1, Stat: 1, LAC: "1A2B", CI: "01C3D4E5", Act: 7}, nil},
+		{` +CREG: 5, "1A2B", "01C3D4E5", 2`, Registration{N: -1, Stat: 5, LAC: "1A2B", CI: "01C3D4E5", Act: 2}, nil},
+		// malformed
+		{"+CREG: x,1", Registration{}, ErrMalformed},
+		{"+CREG: 0,x", Registration{}, ErrMalformed},
+		{`+CREG: 2,1,"1A2B","01C3D4E5",x`, Registration{}, ErrMalformed},
+		{"+CREG: 1,2,3,4,5,6", Registration{}, ErrMalformed},
+		{"+CREG:", Registration{}, ErrMalformed},
+		{"OK", Registration{}, ErrNoData},
+	}
+	for _, tt := range tests {
+		got, err := ParseCREG(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCREG(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	for stat, want := range map[int]bool{
+		NotRegistered:     false,
+		RegisteredHome:    true,
+		Searching:         false,
+		Denied:            false,
+		UnknownState:      false,
+		RegisteredRoaming: true,
+	} {
+		if got := (Registration{Stat: stat}).Registered(); got != want {
+			t.Errorf("stat %d: Registered() = %v, want %v", stat, got, want)
+		}
+	}
+}
+
+func TestParseCMGL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Message
+		err  error
+	}{
+		{
+			"AT+CMGL=\"ALL\"\r\r\n" +
+				"+CMGL: 1,\"REC UNREAD\",\"+46701234567\",,\"26/10/14,10:00:00+08\"\r\nHello, world\r\n" +
+				"+CMGL: 2,\"REC READ\",\"+46707654321\",\"Bob\",\"26/10/14,10:05:00+08\"\r\nline one\r\nline two\r\n" +
+				"\r\nOK\r\n",
+			[]Message{
+				{Index: 1, Status: "REC UNREAD", Sender: "+46701234567", Time: "26/10/14,10:00:00+08", Text: "Hello, world"},
+				{Index: 2, Status: "REC READ", Sender: "+46707654321", Alpha: "Bob", Time: "26/10/14,10:05:00+08", Text: "line one\nline two"},
+			},
+			nil,
+		},
+		{`+CMGL: 3,"STO UNSENT","+4670"` + "\r\n\r\nOK", []Message{{Index: 3, Status: "STO UNSENT", Sender: "+4670"}}, nil},
+		{"\r\nOK\r\n", nil, nil},
+		{`+CMGL: 1,"REC READ"`, nil, ErrMalformed},
+		{`+CMGL: x,"REC READ","+4670"`, nil, ErrMalformed},
+	}
+	for _, tt := range tests {
+		got, err := ParseCMGL(tt.in)
+		if !reflect.DeepEqual(got, tt.want) || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCMGL(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseCGDCONT(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []PDPContext
+		err  error
+	}{
+		{
+			"+CGDCONT: 1,\"IP\",\"internet\",\"10.0.0.2\",0,0\r\n+CGDCONT: 2,\"IPV4V6\",\"ims\"\r\nOK",
+			[]PDPContext{{CID: 1, Type: "IP", APN: "internet", Addr: "10.0.0.2"}, {CID: 2, Type: "IPV4V6", APN: "ims"}},
+			nil,
+		},
+		{`+CGDCONT: 1,"IP",""`, []PDPContext{{CID: 1, Type: "IP"}}, nil},
+		{"OK", nil, nil},
+		{`+CGDCONT: 1,"IP"`, nil, ErrMalformed},
+		{`+CGDCONT: x,"IP","internet"`, nil, ErrMalformed},
+	}
+	for _, tt := range tests {
+		got, err := ParseCGDCONT(tt.in)
+		if !reflect.DeepEqual(got, tt.want) || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCGDCONT(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseCRSM(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SIMResponse
+		ok   bool
+		err  error
+	}{
+		{`+CRSM: 144,0,"42F0101300F1"`, SIMResponse{SW1: 0x90, SW2: 0, Data: []byte{0x42, 0xf0, 0x10, 0x13, 0x00, 0xf1}}, true, nil},
+		{"+CRSM: 145,16", SIMResponse{SW1: 0x91, SW2: 0x10}, true, nil},
+		{`+CRSM: 106,130,""`, SIMResponse{SW1: 0x6a, SW2: 0x82}, false, nil},
+		{`+CRSM: 144,0,"4"`, SIMResponse{}, false, ErrMalformed},
+		{"+CRSM: 144", SIMResponse{}, false, ErrMalformed},
+		{"+CRSM: x,0", SIMResponse{}, false, ErrMalformed},
+		{"ERROR", SIMResponse{}, false, ErrNoData},
+	}
+	for _, tt := range tests {
+		got, err := ParseCRSM(tt.in)
+		if !reflect.DeepEqual(got, tt.want) || got.OK() != tt.ok || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCRSM(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParsePLMNs(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want []string
+	}{
+		{[]byte{0x42, 0xf0, 0x10}, []string{"24001"}},
+		{[]byte{0x42, 0xf0, 0x10, 0xff, 0xff, 0xff, 0x13, 0x00, 0x62}, []string{"24001", "310260"}},
+		{[]byte{0xff, 0xff, 0xff}, nil},
+		{[]byte{0x42, 0xf0}, nil},
+	}
+	for _, tt := range tests {
+		if got := ParsePLMNs(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePLMNs(% x) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// checkErr fails when a parser returns an error that wraps neither
+// ErrMalformed nor ErrNoData.
+func checkErr(t *testing.T, in string, err error) {
+	if err != nil && !errors.Is(err, ErrMalformed) && !errors.Is(err, ErrNoData) {
+		t.Errorf("%q: error %v wraps neither ErrMalformed nor ErrNoData", in, err)
+	}
+}
+
+func FuzzParseCSQ(f *testing.F) {
+	for _, s := range []string{"+CSQ: 17,99\r\nOK", "+CSQ: 99", "+CSQ:", "OK"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		_, err := ParseCSQ(in)
+		checkErr(t, in, err)
+	})
+}
+
+func FuzzParseCOPS(f *testing.F) {
+	for _, s := range []string{`+COPS: 0,0,"Tele2 SE",7`, "+COPS: 0", `+COPS: 0,0,"A,B"`, "+COPS:"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		_, err := ParseCOPS(in)
+		checkErr(t, in, err)
+	})
+}
+
+func FuzzParseCREG(f *testing.F) {
+	for _, s := range []string{
+		"+CREG: 0,1",
+		`+CREG: 2,1,"1A2B","01C3D4E5",7`,
+		`+CREG: 1,"1A2B","01C3D4E5",7`,
+		`+CEREG: 1,"1A2B","01C3D4E5"`,
+		"+CGREG: 3",
+		"+CREG:",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		_, err := ParseCREG(in)
+		checkErr(t, in, err)
+	})
+}
+
+func FuzzParseCMGL(f *testing.F) {
+	for _, s := range []string{
+		"+CMGL: 1,\"REC UNREAD\",\"+4670\",,\"26/10/14,10:00:00+08\"\r\nHi\r\nOK",
+		"+CMGL: 1,\"REC READ\"",
+		"\r\nOK\r\n",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		msgs, err := ParseCMGL(in)
+		checkErr(t, in, err)
+		if err != nil && msgs != nil {
+			t.Errorf("%q: messages returned with error %v", in, err)
+		}
+	})
+}
+
+func FuzzParseCGDCONT(f *testing.F) {
+	for _, s := range []string{"+CGDCONT: 1,\"IP\",\"internet\",\"10.0.0.2\",0,0\r\nOK", `+CGDCONT: 1,"IP"`, "OK"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		ctxs, err := ParseCGDCONT(in)
+		checkErr(t, in, err)
+		if err != nil && ctxs != nil {
+			t.Errorf("%q: contexts returned with error %v", in, err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package atparse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Groups splits a list of parenthesized groups, as in "(1,"A"),(2,"B")",
+// into the text inside each group. Parentheses and commas inside double
+// quotes are kept. Text outside groups, like the empty fields separating
+// the supported modes of AT+COPS=?, is returned as it is, so the caller
+// can tell where the list ends.
+func Groups(s string) []string {
+	var groups []string
+	var b strings.Builder
+	quoted, depth := false, 0
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case quoted:
+			b.WriteRune(r)
+		case r == '(':
+			if depth > 0 {
+				b.WriteRune(r)
+			}
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+			if depth > 0 {
+				b.WriteRune(r)
+			}
+		case r == ',' && depth == 0:
+			groups = append(groups, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(groups, strings.TrimSpace(b.String()))
+}
+
+// Operator availability reported by AT+COPS=?.
+const (
+	OperatorUnknown = iota
+	OperatorAvailable
+	OperatorCurrent
+	OperatorForbidden
+)
+
+// NetworkOperator is one network of an AT+COPS=? scan.
+type NetworkOperator struct {
+	Stat    int
+	Long    string
+	Short   string
+	Numeric string // MCC and MNC, e.g. 24001
+	Act     int    // access technology, -1 when not reported
+}
+
+// ParseCOPSList parses the reply to a network scan:
+//
+//	+COPS: (<stat>,<long>,<short>,<numeric>[,<AcT>]),...,,(<modes>),(<formats>)
+//
+// The supported modes and formats after the empty field are ignored.
+func ParseCOPSList(resp string) ([]NetworkOperator, error) {
+	p, err := first(resp, "+COPS")
+	if err != nil {
+		return nil, err
+	}
+	var ops []NetworkOperator
+	for _, g := range Groups(p) {
+		if g == "" {
+			break
+		}
+		f := Fields(g)
+		if len(f) < 4 || len(f) > 5 {
+			return nil, malformed("+COPS", p)
+		}
+		stat, err := strconv.Atoi(f[0])
+		if err != nil {
+			return nil, malformed("+COPS", p)
+		}
+		op := NetworkOperator{Stat: stat, Long: f[1], Short: f[2], Numeric: f[3], Act: -1}
+		if len(f) == 5 {
+			if op.Act, err = atoi(f[4], -1); err != nil {
+				return nil, malformed("+COPS", p)
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// PhonebookEntry is one entry of an AT+CPBR listing.
+type PhonebookEntry struct {
+	Index  int
+	Number string
+	Type   int // type of address, 145 for international numbers
+	Text   string
+}
+
+// ParseCPBR parses "+CPBR: <index>,<number>,<type>,<text>" lines. Texts
+// may hold commas.
+func ParseCPBR(resp string) ([]PhonebookEntry, error) {
+	var entries []PhonebookEntry
+	for _, p := range params(resp, "+CPBR") {
+		f := Fields(p)
+		if len(f) < 4 {
+			return nil, malformed("+CPBR", p)
+		}
+		idx, err1 := strconv.Atoi(f[0])
+		typ, err2 := strconv.Atoi(f[2])
+		if err1 != nil || err2 != nil {
+			return nil, malformed("+CPBR", p)
+		}
+		entries = append(entries, PhonebookEntry{Index: idx, Number: f[1], Type: typ, Text: f[3]})
+	}
+	return entries, nil
+}
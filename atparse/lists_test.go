@@ -0,0 +1,80 @@
+package atparse
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGroups(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`(1,"A"),(2,"B")`, []string{`1,"A"`, `2,"B"`}},
+		{`(1,"A (x)"),,(0,1),(0,2)`, []string{`1,"A (x)"`, "", "0,1", "0,2"}},
+		{`(1,"a,b")`, []string{`1,"a,b"`}},
+		{"((1,2),3)", []string{"(1,2),3"}},
+		{"", []string{""}},
+	}
+	for _, tt := range tests {
+		if got := Groups(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Groups(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCOPSList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []NetworkOperator
+		err  error
+	}{
+		{
+			`+COPS: (2,"Tele2 SE","Tele2","24007",7),(3,"Telia S","Telia","24001",2),,(0,1,2,3,4),(0,1,2)` + "\r\nOK",
+			[]NetworkOperator{
+				{Stat: OperatorCurrent, Long: "Tele2 SE", Short: "Tele2", Numeric: "24007", Act: 7},
+				{Stat: OperatorForbidden, Long: "Telia S", Short: "Telia", Numeric: "24001", Act: 2},
+			},
+			nil,
+		},
+		{`+COPS: (1,"A","B","24002")`, []NetworkOperator{{Stat: OperatorAvailable, Long: "A", Short: "B", Numeric: "24002", Act: -1}}, nil},
+		{"+COPS: ,,(0,1),(0,2)", nil, nil},
+		{`+COPS: (1,"A","B")`, nil, ErrMalformed},
+		{`+COPS: (x,"A","B","24002")`, nil, ErrMalformed},
+		{`+COPS: (1,"A","B","24002",x)`, nil, ErrMalformed},
+		{"OK", nil, ErrNoData},
+	}
+	for _, tt := range tests {
+		got, err := ParseCOPSList(tt.in)
+		if !reflect.DeepEqual(got, tt.want) || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCOPSList(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseCPBR(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []PhonebookEntry
+		err  error
+	}{
+		{
+			"+CPBR: 1,\"+46701234567\",145,\"Alice, home\"\r\n+CPBR: 2,\"0701234567\",129,\"Bob\"\r\nOK",
+			[]PhonebookEntry{
+				{Index: 1, Number: "+46701234567", Type: 145, Text: "Alice, home"},
+				{Index: 2, Number: "0701234567", Type: 129, Text: "Bob"},
+			},
+			nil,
+		},
+		{"OK", nil, nil},
+		{`+CPBR: 1,"+4670",145`, nil, ErrMalformed},
+		{`+CPBR: 1,"+4670",x,"A"`, nil, ErrMalformed},
+	}
+	for _, tt := range tests {
+		got, err := ParseCPBR(tt.in)
+		if !reflect.DeepEqual(got, tt.want) || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCPBR(%q) = %+v, %v, want %+v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
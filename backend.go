@@ -0,0 +1,61 @@
+package modem
+
+import "time"
+
+// Device is a kernel device as reported by a Backend. Attribute reads may
+// be lazy, the manager calls only what it needs for each event.
+type Device interface {
+	// add, remove or change for hotplug events, empty during enumeration.
+	Action() string
+	Subsystem() string
+	// Kernel name, e.g. ttyUSB2 or wwan0.
+	SysName() string
+	// Device node, e.g. /dev/ttyUSB2. Empty for network interfaces.
+	DevNode() string
+	// Sysfs attribute of the device itself.
+	Attr(name string) string
+	// Attribute of the USB interface the device belongs to.
+	InterfaceAttr(name string) string
+	// USB device the device belongs to, nil if it is not on USB.
+	USBDevice() Device
+}
+
+// PropertyDevice is a Device with udev properties, such as ID_MODEL or
+// ID_USB_INTERFACE_NUM.
+type PropertyDevice interface {
+	Device
+	Property(name string) string
+}
+
+// Property returns a udev property of dev, empty if its backend has none.
+func Property(dev Device, name string) string {
+	if p, ok := dev.(PropertyDevice); ok {
+		return p.Property(name)
+	}
+	return ""
+}
+
+// Backend discovers devices and feeds their events to the manager.
+// The default backend reads udev.
+type Backend interface {
+	// Run reports the devices already present, then hotplug events, to
+	// handle until stop is closed. handle must be called from one goroutine.
+	Run(stop <-chan struct{}, handle func(Device)) error
+}
+
+// IdentifiedDevice is a tty whose backend already knows the modem behind
+// it. The manager adopts it as the modem's command port without probing.
+type IdentifiedDevice interface {
+	Device
+	Imei() string
+}
+
+// PortOwner is implemented by backends that own the modem ports, such as
+// ModemManager. The manager never opens the ports of their modems and
+// routes requests through the backend instead.
+type PortOwner interface {
+	Command(d Modem, cmd string, timeout time.Duration) (string, error)
+	SendSMS(d Modem, number string, text string) error
+	Connect(d Modem, apn string) error
+	Disconnect(d Modem) error
+}
@@ -0,0 +1,73 @@
+package modem
+
+// fakeDevice is a Device with fixed attributes.
+type fakeDevice struct {
+	action    string
+	subsystem string
+	name      string
+	node      string
+	attrs     map[string]string
+	iface     map[string]string
+	usb       *fakeDevice
+}
+
+func (d *fakeDevice) Action() string                   { return d.action }
+func (d *fakeDevice) Subsystem() string                { return d.subsystem }
+func (d *fakeDevice) SysName() string                  { return d.name }
+func (d *fakeDevice) DevNode() string                  { return d.node }
+func (d *fakeDevice) Attr(name string) string          { return d.attrs[name] }
+func (d *fakeDevice) InterfaceAttr(name string) string { return d.iface[name] }
+
+func (d *fakeDevice) USBDevice() Device {
+	if d.usb == nil {
+		return nil
+	}
+	return d.usb
+}
+
+// identifiedDevice is a tty whose IMEI is known, as ModemManager reports
+// them, so it is adopted without a probe.
+type identifiedDevice struct {
+	*fakeDevice
+	imei string
+}
+
+func (d identifiedDevice) Imei() string { return d.imei }
+
+// fakeBackend reports its devices, then waits for the monitor to stop.
+type fakeBackend struct {
+	devices []Device
+}
+
+func (b fakeBackend) Run(stop <-chan struct{}, handle func(Device)) error {
+	for _, dev := range b.devices {
+		handle(dev)
+	}
+	<-stop
+	return nil
+}
+
+// usbModem returns the USB device of a modem on port 1-<n> and its
+// command tty /dev/ttyUSB<n>, reported with the given IMEI.
+func usbModem(n string, imei string) (*fakeDevice, identifiedDevice) {
+	usb := &fakeDevice{
+		subsystem: "usb",
+		name:      "1-" + n,
+		node:      "/dev/bus/usb/001/00" + n,
+		attrs:     map[string]string{"idVendor": "12d1", "idProduct": "1001"},
+	}
+	tty := &fakeDevice{
+		action:    "add",
+		subsystem: "tty",
+		name:      "ttyUSB" + n,
+		node:      "/dev/ttyUSB" + n,
+		iface:     map[string]string{"bInterfaceNumber": "00", "bNumEndpoints": "03"},
+		usb:       usb,
+	}
+	return usb, identifiedDevice{tty, imei}
+}
+
+// unplug returns the remove event of a USB device.
+func unplug(usb *fakeDevice) *fakeDevice {
+	return &fakeDevice{action: "remove", subsystem: "usb", name: usb.name, node: usb.node}
+}
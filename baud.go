@@ -0,0 +1,50 @@
+package modem
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+var defaultBaudRates = []int{115200, 9600, 460800}
+
+// How long to wait for the OK to an AT at each candidate baud rate.
+const detectTimeout = time.Millisecond * 500
+
+// openPort opens a serial port at its baud rate. The first open of a port
+// tries the candidate rates until an AT gets an OK and remembers the one
+// that did until the modem is removed. When no rate gets an answer the
+// port is opened at the first one, and detection is retried next time.
+// A baud rate set in a DeviceConfig is used as is.
+func (m *Manager) openPort(node string) (io.ReadWriteCloser, error) {
+	m.mu.Lock()
+	baud, ok := m.bauds[node]
+	if c := m.overrides[node]; c.Baud > 0 {
+		baud, ok = c.Baud, true
+	}
+	rates := m.baudRates
+	m.mu.Unlock()
+	if ok {
+		return m.dial(node, baud)
+	}
+	for _, rate := range rates {
+		rw, err := m.dial(node, rate)
+		if err != nil {
+			return nil, err
+		}
+		p := newATPort(m, context.Background(), node, "", rw, nil)
+		// A port failing here is closed and tried at the next rate, not
+		// reopened, which would detect it again and replay the setup.
+		p.reopening = true
+		if _, err := p.Command("AT", detectTimeout); err == nil {
+			m.log.Debug("baud rate detected", "tty", node, "baud", rate)
+			m.mu.Lock()
+			m.bauds[node] = rate
+			m.mu.Unlock()
+			return rw, nil
+		}
+		rw.Close()
+	}
+	m.log.Warn("baud rate not detected", "tty", node, "baud", rates[0])
+	return m.dial(node, rates[0])
+}
@@ -0,0 +1,75 @@
+/*
+Package boltstore keeps the known modems of a modem.Manager in a bbolt
+database, one JSON record per IMEI.
+
+	db, err := bolt.Open("/var/lib/modem/modems.db", 0600, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := boltstore.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m := modem.New(modem.WithStore(s))
+
+The database may hold other buckets; the records are kept in Bucket.
+*/
+package boltstore
+
+import (
+	"encoding/json"
+
+	"github.com/ausrasul/modem"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Name of the bucket holding the records.
+const Bucket = "modems"
+
+// Store is a modem.Store backed by a bbolt database.
+type Store struct {
+	db *bolt.DB
+}
+
+// New creates the records bucket in db if needed. The caller closes db.
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(Bucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Load() ([]modem.Record, error) {
+	var records []modem.Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(Bucket)).ForEach(func(k, v []byte) error {
+			var r modem.Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			records = append(records, r)
+			return nil
+		})
+	})
+	return records, err
+}
+
+func (s *Store) Save(r modem.Record) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(Bucket)).Put([]byte(r.Imei), v)
+	})
+}
+
+func (s *Store) Delete(imei string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(Bucket)).Delete([]byte(imei))
+	})
+}
@@ -0,0 +1,76 @@
+package boltstore_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/boltstore"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestRoundTrip(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "modems.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err := boltstore.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	with := modem.Record{
+		Imei: "490154203237518", Iccid: "8946000000000000001", Vid: "12d1", Pid: "1001", Alias: "roof",
+		Config:    &modem.DeviceConfig{Baud: 9600, InitCommands: []string{"AT+CFUN=1"}, CommandInterface: "02"},
+		FirstSeen: first, LastSeen: first.Add(time.Hour),
+	}
+	without := modem.Record{Imei: "356938035643809", Vid: "1bc7", Pid: "1201", FirstSeen: first, LastSeen: first}
+	for _, r := range []modem.Record{with, without} {
+		if err := s.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	with.Alias = "basement"
+	if err := s.Save(with); err != nil {
+		t.Fatal(err)
+	}
+	check(t, s, with, without)
+
+	if err := s.Delete(without.Imei); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("000000000000000"); err != nil {
+		t.Errorf("Delete of an unknown IMEI: %v", err)
+	}
+	check(t, s, with)
+}
+
+// check compares the records in s by IMEI, and their times with Equal.
+func check(t *testing.T, s *boltstore.Store, want ...modem.Record) {
+	t.Helper()
+	got, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Load() = %d records, want %d", len(got), len(want))
+	}
+	byImei := make(map[string]modem.Record)
+	for _, r := range got {
+		byImei[r.Imei] = r
+	}
+	for _, w := range want {
+		r := byImei[w.Imei]
+		if !r.FirstSeen.Equal(w.FirstSeen) || !r.LastSeen.Equal(w.LastSeen) {
+			t.Errorf("%s: seen %v to %v, want %v to %v", w.Imei, r.FirstSeen, r.LastSeen, w.FirstSeen, w.LastSeen)
+		}
+		r.FirstSeen, r.LastSeen = w.FirstSeen, w.LastSeen
+		if !reflect.DeepEqual(r, w) {
+			t.Errorf("%s: loaded %+v, want %+v", w.Imei, r, w)
+		}
+	}
+}
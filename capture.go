@@ -0,0 +1,144 @@
+package modem
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// capture mirrors serial traffic to files, one per modem.
+type capture struct {
+	mu    sync.Mutex
+	dir   string
+	since time.Time
+	files map[string]*os.File
+	// Redacted commands in flight on ports, whose traffic is not recorded.
+	hidden map[string]string
+}
+
+// Mirror all serial traffic to capture files in dir, or stop with an
+// empty dir. May be called at any time, ports already open switch too.
+//
+// Each modem gets a file named after its IMEI, or after the port while
+// the modem is being probed, and the time capturing started, e.g.
+// 490154203237518-20261014T153000.cap. Every read and write is a line
+// with a timestamp, the port, > for data sent to the modem or < for data
+// received, and the data as a quoted Go string. Commands carrying a PIN
+// or password are recorded redacted, as AT+CPIN=***, and the data
+// received in reply as ***, since the modem may echo the command.
+func (m *Manager) SetCapture(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	c := &m.capture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range c.files {
+		f.Close()
+	}
+	c.files = nil
+	c.dir = dir
+	c.since = m.clock.Now()
+	return nil
+}
+
+// record appends one read or write on node to the capture, if enabled.
+func (m *Manager) record(node string, dir byte, data []byte) {
+	c := &m.capture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dir == "" || len(data) == 0 {
+		return
+	}
+	if shown, ok := c.hidden[node]; ok {
+		data = []byte("***")
+		if dir == '>' {
+			data = []byte(shown + "\r")
+		}
+	}
+	name := m.captureName(node)
+	f, ok := c.files[name]
+	if !ok {
+		path := filepath.Join(c.dir, name+"-"+c.since.UTC().Format("20060102T150405")+".cap")
+		var err error
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			m.log.Warn("capture failed", "path", path, "err", err)
+			return
+		}
+		if c.files == nil {
+			c.files = make(map[string]*os.File)
+		}
+		c.files[name] = f
+	}
+	fmt.Fprintf(f, "%s %s %c %s\n", m.clock.Now().UTC().Format(time.RFC3339Nano), filepath.Base(node), dir,
+		strconv.Quote(string(data)))
+}
+
+// hideCapture replaces the traffic of node by the redacted command shown
+// until it is called again with an empty shown.
+func (m *Manager) hideCapture(node string, shown string) {
+	c := &m.capture
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if shown == "" {
+		delete(c.hidden, node)
+		return
+	}
+	if c.hidden == nil {
+		c.hidden = make(map[string]string)
+	}
+	c.hidden[node] = shown
+}
+
+// captureName returns the IMEI of the modem owning node, or the port name.
+func (m *Manager) captureName(node string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.devices {
+		if d.Imei == "" {
+			continue
+		}
+		if d.Tty == node {
+			return d.Imei
+		}
+		for _, p := range d.Ports {
+			if p.Node == node {
+				return d.Imei
+			}
+		}
+	}
+	return filepath.Base(node)
+}
+
+// capturePort records the traffic of a port.
+type capturePort struct {
+	io.ReadWriteCloser
+	m    *Manager
+	node string
+}
+
+func (p *capturePort) Write(b []byte) (int, error) {
+	n, err := p.ReadWriteCloser.Write(b)
+	p.m.record(p.node, '>', b[:n])
+	return n, err
+}
+
+func (p *capturePort) Read(b []byte) (int, error) {
+	n, err := p.ReadWriteCloser.Read(b)
+	p.m.record(p.node, '<', b[:n])
+	return n, err
+}
+
+func (p *capturePort) SetReadDeadline(t time.Time) error {
+	if dl, ok := p.ReadWriteCloser.(deadliner); ok {
+		return dl.SetReadDeadline(t)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package modem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureHidesSecrets(t *testing.T) {
+	dir := t.TempDir()
+	m := New()
+	if err := m.SetCapture(dir); err != nil {
+		t.Fatal(err)
+	}
+	m.record("/dev/ttyUSB2", '>', []byte("AT+CPIN?\r"))
+	m.hideCapture("/dev/ttyUSB2", "AT+CPIN=***")
+	m.record("/dev/ttyUSB2", '>', []byte("AT+CPIN=\"1234\"\r"))
+	m.record("/dev/ttyUSB2", '<', []byte("AT+CPIN=\"1234\"\r\r\nOK\r\n"))
+	m.hideCapture("/dev/ttyUSB2", "")
+	m.record("/dev/ttyUSB2", '<', []byte("\r\n+CPIN: READY\r\n"))
+	m.SetCapture("")
+
+	files, _ := filepath.Glob(filepath.Join(dir, "ttyUSB2-*.cap"))
+	if len(files) != 1 {
+		t.Fatalf("capture files %q, want one", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if strings.Contains(got, "1234") {
+		t.Errorf("capture holds the PIN:\n%s", got)
+	}
+	for _, want := range []string{`> "AT+CPIN?\r"`, `> "AT+CPIN=***\r"`, `< "***"`, `+CPIN: READY`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("capture lacks %s:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package modem
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Port roles by USB interface number of known modems, for the ports that
+// can't be told apart by probing.
+var portTables = map[filter]map[string]PortRole{
+	// Quectel EC21, EC25, EG25-G, EG95, BG96, EP06 and EM12. Their AT
+	// and modem ports both have three endpoints.
+	{vid: "2c7c", pid: "0121"}: quectelPorts,
+	{vid: "2c7c", pid: "0125"}: quectelPorts,
+	{vid: "2c7c", pid: "0195"}: quectelPorts,
+	{vid: "2c7c", pid: "0296"}: quectelPorts,
+	{vid: "2c7c", pid: "0306"}: quectelPorts,
+	{vid: "2c7c", pid: "0512"}: quectelPorts,
+	// Sierra Wireless MC7304, MC7354
+	{vid: "1199", pid: "68c0"}: {"00": PortDiag, "02": PortNMEA, "03": PortCommand},
+}
+
+var quectelPorts = map[string]PortRole{"00": PortDiag, "01": PortNMEA, "02": PortCommand, "03": PortData}
+
+// tableRole looks up the role of a port in portTables.
+func tableRole(vid, pid, iface string) (PortRole, bool) {
+	role, ok := portTables[filter{vid: vid, pid: pid}][iface]
+	return role, ok
+}
+
+// USB interface class, subclass and protocol of ports that only speak a
+// vendor's diagnostics protocol. They are never sent AT.
+var diagInterfaces = map[string]bool{
+	"ff/ff/30": true, // Qualcomm DM, as on Quectel and Telit modems
+}
+
+// diagInterface reports whether a tty is a diagnostics port, going by
+// its USB interface descriptor.
+func diagInterface(dev Device) bool {
+	return diagInterfaces[dev.InterfaceAttr("bInterfaceClass")+"/"+
+		dev.InterfaceAttr("bInterfaceSubClass")+"/"+dev.InterfaceAttr("bInterfaceProtocol")]
+}
+
+// How long an unknown port is listened to when classifying it.
+const classifyTimeout = time.Second * 2
+
+// unclassified queues a port that isn't a command port candidate and
+// whose role neither a table nor its interface tells. Ports of a ready
+// modem are classified at once, the others once their modem is ready.
+func (m *Manager) unclassified(stop chan struct{}, key string, port Port) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.devices[key].State != StateReady {
+		m.pending[key] = append(m.pending[key], port)
+		return
+	}
+	m.workers.Add(1)
+	go m.classify(stop, key, []Port{port})
+}
+
+// classifyPendingLocked starts classifying the ports queued for a modem
+// that just became ready. m.mu must be held.
+func (m *Manager) classifyPendingLocked(stop chan struct{}, key string) {
+	ports := m.pending[key]
+	delete(m.pending, key)
+	if len(ports) > 0 {
+		m.workers.Add(1)
+		go m.classify(stop, key, ports)
+	}
+}
+
+// classify finds out the roles of ports of a ready modem: a port
+// answering AT is a data port, one sending NMEA sentences is an NMEA port
+// and a silent one is taken for diagnostics. NMEA ports that only talk
+// once GNSS is enabled are classified as Diag. The modem settled and its
+// baud rate is known by then, so no probe slot is taken and the rate of
+// the command port is used.
+func (m *Manager) classify(stop chan struct{}, key string, ports []Port) {
+	defer m.workers.Done()
+	m.mu.Lock()
+	baud, ok := m.bauds[m.devices[key].Tty]
+	m.mu.Unlock()
+	if !ok {
+		baud = m.baudRates[0]
+	}
+	for _, port := range ports {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		role, err := m.listen(port.Node, baud)
+		if err != nil {
+			m.log.Debug("port not classified", "usb", key, "tty", port.Node, "err", err)
+			continue
+		}
+		port.Role = role
+		m.log.Debug("port classified", "usb", key, "tty", port.Node, "role", port.Role)
+		m.setPort(key, port)
+	}
+}
+
+// listen sends AT on a port and tells its role from the reply.
+func (m *Manager) listen(node string, baud int) (PortRole, error) {
+	m.mu.Lock()
+	if c := m.overrides[node]; c.Baud > 0 {
+		baud = c.Baud
+	}
+	m.mu.Unlock()
+	l := m.portLock(node)
+	l.Lock()
+	rw, err := m.dial(node, baud)
+	if err != nil {
+		l.Unlock()
+		return 0, err
+	}
+	p := newATPort(m, context.Background(), node, "", rw, l)
+	// A port failing here is not a command port, don't reopen it.
+	p.reopening = true
+	resp, err := p.Command("AT", classifyTimeout)
+	p.Close()
+	switch {
+	case err == nil:
+		m.mu.Lock()
+		m.bauds[node] = baud
+		m.mu.Unlock()
+		return PortData, nil
+	case strings.Contains(resp, "$G"):
+		return PortNMEA, nil
+	}
+	return PortDiag, nil
+}
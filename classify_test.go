@@ -0,0 +1,77 @@
+package modem_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/modemtest"
+)
+
+func TestClassifyPorts(t *testing.T) {
+	command, err := modemtest.NewLoopback(
+		modemtest.Exchange{Expect: "AT", Send: "\r\nOK\r\n"},
+		modemtest.Exchange{Expect: "AT+CGSN", Send: "\r\n" + imei + "\r\n\r\nOK\r\n"},
+		modemtest.Exchange{Expect: "AT+CCID", Send: "\r\n+CCID: 8946000000000000001\r\n\r\nOK\r\n"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer command.Close()
+	data, err := modemtest.NewLoopback(modemtest.Exchange{Expect: "AT", Send: "\r\nOK\r\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.Close()
+
+	modem.SetLockDir(t.TempDir())
+	usb, _ := modem.FakeModem("1", imei, "")
+	const dm = "/dev/modemtest-dm" // never opened
+	devs := []modem.Device{
+		modem.FakeTty(usb, data.Path, map[string]string{"bInterfaceNumber": "01", "bNumEndpoints": "02",
+			"bInterfaceClass": "ff", "bInterfaceSubClass": "00", "bInterfaceProtocol": "00"}),
+		modem.FakeTty(usb, dm, map[string]string{"bInterfaceNumber": "00", "bNumEndpoints": "02",
+			"bInterfaceClass": "ff", "bInterfaceSubClass": "ff", "bInterfaceProtocol": "30"}),
+		modem.FakeTty(usb, command.Path, map[string]string{"bInterfaceNumber": "02", "bNumEndpoints": "03"}),
+	}
+	m := modem.New(modem.WithBackend(modem.FakeBackend(devs...)))
+	m.SetClock(modemtest.NewClock(time.Unix(0, 0)))
+	m.AddFilter("12d1", "1001")
+	events := m.Events()
+	if err := m.Monitor(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.StopMonitor()
+	if ev := <-events; ev.Type != modem.EventAdd || ev.Modem.Tty != command.Path {
+		t.Fatalf("event %s on %s, want add on %s", ev.Type, ev.Modem.Tty, command.Path)
+	}
+
+	// The data port is classified once the modem is ready, and announced
+	// with an update.
+	want := map[string]modem.PortRole{command.Path: modem.PortCommand, data.Path: modem.PortData, dm: modem.PortDiag}
+	roles := make(map[string]modem.PortRole)
+	for len(roles) < len(want) {
+		select {
+		case ev := <-events:
+			if ev.Type != modem.EventUpdate {
+				t.Fatalf("event %s, want update", ev.Type)
+			}
+			clear(roles)
+			for _, p := range ev.Modem.Ports {
+				roles[p.Node] = p.Role
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no update with every port, last ports %v", roles)
+		}
+	}
+	for node, role := range want {
+		if got, ok := roles[node]; !ok || got != role {
+			t.Errorf("%s: role %s, want %s", node, got, role)
+		}
+	}
+	for _, l := range []*modemtest.Loopback{command, data} {
+		if err := l.Err(); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package modem
+
+import "time"
+
+// Clock is the time source behind every internal sleep, delay and timeout,
+// except the udev backend's wait for device events. Replace it in tests to
+// make time dependent code run instantly.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Replace the manager's clock. Must be called before Monitor.
+func (m *Manager) SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+	m.clock = c
+}
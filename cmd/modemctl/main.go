@@ -0,0 +1,254 @@
+/*
+Command modemctl lists and drives the USB modems attached to this host.
+
+Usage:
+
+	modemctl [flags] list
+	modemctl [flags] watch
+	modemctl [flags] imei
+	modemctl [flags] signal
+	modemctl [flags] at <command>
+	modemctl [flags] sms send <number> <text>
+	modemctl [flags] reset
+	modemctl [flags] fplmn [clear]
+
+Modems are matched by -f vid:pid filters, or by the filters of the
+configuration file given with -c (see modem.LoadConfig). Commands acting on one modem use
+the one given with -m, or the only modem present.
+
+While watching, SIGHUP reloads the configuration file (see modem.Manager.Reload).
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ausrasul/modem"
+)
+
+// Filters used when none are given with -f.
+const defaultFilters = "1199:68a3,12d1:1001,12d1:1506"
+
+func main() {
+	filters := flag.String("f", "", "comma separated `vid:pid` list of modems to manage (default "+defaultFilters+")")
+	config := flag.String("c", "", "configuration `file`, YAML or JSON")
+	imei := flag.String("m", "", "`IMEI` of the modem to act on")
+	wait := flag.Duration("wait", 3*time.Second, "time to wait for modems to be probed")
+	settle := flag.Duration("settle", 5*time.Second, "delay before probing a newly plugged modem")
+	verbose := flag.Bool("v", false, "log probe decisions and rejected devices to stderr")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	opts := []modem.Option{modem.WithSettleDelay(*settle)}
+	configured := false
+	if *config != "" {
+		cfg, err := modem.LoadConfig(*config)
+		if err != nil {
+			fatal(err)
+		}
+		opts = append(opts, modem.WithConfig(cfg))
+		configured = len(cfg.Filters) > 0
+	}
+	if *filters == "" && !configured {
+		*filters = defaultFilters
+	}
+	if *verbose {
+		opts = append(opts, modem.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	}
+	m := modem.New(opts...)
+	if *verbose {
+		m.SetRejectHandler(func(r modem.RejectedDevice) {
+			fmt.Fprintf(os.Stderr, "rejected %s (%s:%s): %s %v\n", r.Name, r.Vid, r.Pid, r.Reason, r.Err)
+		})
+	}
+	if *filters != "" {
+		for _, f := range strings.Split(*filters, ",") {
+			vid, pid, ok := strings.Cut(strings.TrimSpace(f), ":")
+			if !ok {
+				fatal(fmt.Errorf("Invalid filter %q, expected vid:pid", f))
+			}
+			m.AddFilter(vid, pid)
+		}
+	}
+
+	c := &ctl{m: m, imei: *imei, wait: *wait, config: *config}
+	if err := c.run(flag.Args()); err != nil {
+		fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: modemctl [flags] list|watch|imei|signal|reset|at <command>|sms send <number> <text>|fplmn [clear]")
+	flag.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "modemctl:", err)
+	os.Exit(1)
+}
+
+type ctl struct {
+	m      *modem.Manager
+	imei   string
+	wait   time.Duration
+	config string
+}
+
+func (c *ctl) run(args []string) error {
+	if err := c.m.Monitor(); err != nil {
+		return err
+	}
+	defer c.m.StopMonitor()
+
+	switch args[0] {
+	case "watch":
+		return c.watch()
+	case "list":
+		time.Sleep(c.wait)
+		return c.list()
+	case "imei":
+		time.Sleep(c.wait)
+		for _, d := range sorted(c.m.List()) {
+			fmt.Println(d.Imei)
+		}
+		return nil
+	}
+
+	time.Sleep(c.wait)
+	target, err := c.target()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "signal":
+		s, err := c.m.Signal(target)
+		if err != nil {
+			return err
+		}
+		if !s.Known() {
+			fmt.Println("unknown")
+			return nil
+		}
+		fmt.Printf("%d dBm (rssi %d, ber %d)\n", s.DBm(), s.RSSI, s.BER)
+		return nil
+	case "at":
+		if len(args) != 2 {
+			return errors.New("usage: at <command>")
+		}
+		resp, err := c.m.SendAT(target, args[1])
+		if resp != "" {
+			fmt.Println(resp)
+		}
+		return err
+	case "sms":
+		if len(args) != 4 || args[1] != "send" {
+			return errors.New("usage: sms send <number> <text>")
+		}
+		return c.m.SendSMS(target, args[2], args[3])
+	case "reset":
+		_, err := c.m.SendAT(target, "AT+CFUN=1,1")
+		return err
+	case "fplmn":
+		if len(args) == 2 && args[1] == "clear" {
+			return c.m.ClearForbiddenNetworks(target)
+		}
+		if len(args) != 1 {
+			return errors.New("usage: fplmn [clear]")
+		}
+		plmns, err := c.m.ForbiddenNetworks(target)
+		for _, p := range plmns {
+			fmt.Println(p)
+		}
+		return err
+	}
+	return fmt.Errorf("Unknown command %q", args[0])
+}
+
+// target returns the IMEI of the modem to act on.
+func (c *ctl) target() (string, error) {
+	if c.imei != "" {
+		return c.imei, nil
+	}
+	list := c.m.List()
+	switch len(list) {
+	case 0:
+		return "", errors.New("No modem found")
+	case 1:
+		for _, d := range list {
+			return d.Imei, nil
+		}
+	}
+	return "", errors.New("Several modems found, pick one with -m")
+}
+
+func (c *ctl) list() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "IMEI\tALIAS\tTTY\tNET")
+	for _, d := range sorted(c.m.List()) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Imei, d.Alias, d.Tty, d.Net)
+	}
+	return w.Flush()
+}
+
+func (c *ctl) watch() error {
+	events := c.m.Events()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGHUP)
+	for {
+		select {
+		case s := <-sig:
+			if s == syscall.SIGHUP {
+				c.reload()
+				continue
+			}
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("%s %-6s %s %s %s\n", time.Now().Format(time.TimeOnly), ev.Type, ev.Modem.Imei, ev.Modem.Tty, ev.Modem.Net)
+		}
+	}
+}
+
+// reload applies the configuration file again, keeping the current one
+// if the file is invalid.
+func (c *ctl) reload() {
+	if c.config == "" {
+		fmt.Fprintln(os.Stderr, "modemctl: no configuration file to reload, see -c")
+		return
+	}
+	cfg, err := modem.LoadConfig(c.config)
+	if err == nil {
+		err = c.m.Reload(cfg)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "modemctl: reload:", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "modemctl: configuration reloaded")
+}
+
+// sorted returns the modems ordered by IMEI.
+func sorted(list map[string]modem.Modem) []modem.Modem {
+	out := make([]modem.Modem, 0, len(list))
+	for _, d := range list {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Imei < out[j].Imei })
+	return out
+}
@@ -0,0 +1,200 @@
+package modem
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the manager configuration that can be kept in a file, see
+// LoadConfig and WithConfig.
+type Config struct {
+	Filters      []Filter          `json:"filters" yaml:"filters"`
+	SettleDelay  Duration          `json:"settle_delay" yaml:"settle_delay"`
+	SMSPoll      Duration          `json:"sms_poll" yaml:"sms_poll"`
+	PINs         map[string]string `json:"pins" yaml:"pins"` // SIM PIN by ICCID
+	APN          string            `json:"apn" yaml:"apn"`   // used by Connect when no APN is given
+	APNs         map[string]string `json:"apns" yaml:"apns"` // APN by ICCID, overriding APN
+	InitCommands []string          `json:"init_commands" yaml:"init_commands"`
+	Serial       Line              `json:"serial" yaml:"serial"`
+	// Delete polled messages once received, see WithSMSDelete.
+	SMSDelete bool `json:"sms_delete" yaml:"sms_delete"`
+	// Modem labels by IMEI or USB port path, see SetAlias.
+	Aliases map[string]string `json:"aliases" yaml:"aliases"`
+	// Commands run on modem events.
+	Exec []ExecHook `json:"exec" yaml:"exec"`
+	// Overrides by "vid:pid" or "vid:pid:serial", see SetDeviceConfig.
+	Devices map[string]DeviceConfig `json:"devices" yaml:"devices"`
+	// Actions run periodically on every ready modem.
+	Tasks []Task `json:"tasks" yaml:"tasks"`
+	// AT commands SendAT may send.
+	Commands CommandPolicy `json:"commands" yaml:"commands"`
+}
+
+// Vendor and product id pair, as given to AddFilter.
+type Filter struct {
+	Vid string `json:"vid" yaml:"vid"`
+	Pid string `json:"pid" yaml:"pid"`
+}
+
+// Duration is a time.Duration written as "5s" or "1m30s" in files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+var (
+	hexID    = regexp.MustCompile(`^[0-9a-fA-F]{4}$`)
+	pinRe    = regexp.MustCompile(`^[0-9]{4,8}$`)
+	iccidRe  = regexp.MustCompile(`^[0-9]{18,22}$`)
+	atPrefix = regexp.MustCompile(`^(?i)AT`)
+)
+
+// Load a configuration from a YAML (.yaml, .yml) or JSON file and validate
+// it. Unknown keys are errors, so misspelled ones are not ignored.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(cfg); err == io.EOF {
+			err = nil // empty file
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reports every problem found in the configuration.
+func (c *Config) Validate() error {
+	var errs []error
+	for i, f := range c.Filters {
+		if !hexID.MatchString(f.Vid) || !hexID.MatchString(f.Pid) {
+			errs = append(errs, fmt.Errorf("filters[%d]: vid and pid must be 4 hex digits", i))
+		}
+	}
+	if c.SettleDelay < 0 {
+		errs = append(errs, errors.New("settle_delay must not be negative"))
+	}
+	if c.SMSPoll < 0 {
+		errs = append(errs, errors.New("sms_poll must not be negative"))
+	}
+	for iccid, pin := range c.PINs {
+		if !iccidRe.MatchString(iccid) {
+			errs = append(errs, fmt.Errorf("pins: invalid ICCID %q", iccid))
+		}
+		if !pinRe.MatchString(pin) {
+			errs = append(errs, fmt.Errorf("pins: PIN for %s must be 4 to 8 digits", iccid))
+		}
+	}
+	for iccid := range c.APNs {
+		if !iccidRe.MatchString(iccid) {
+			errs = append(errs, fmt.Errorf("apns: invalid ICCID %q", iccid))
+		}
+	}
+	for i, cmd := range c.InitCommands {
+		if !atPrefix.MatchString(cmd) || strings.ContainsAny(cmd, "\r\n\x1a") {
+			errs = append(errs, fmt.Errorf("init_commands[%d]: %q is not a single AT command", i, cmd))
+		}
+	}
+	errs = append(errs, c.Serial.validate())
+	errs = append(errs, c.Commands.validate())
+	for key, dc := range c.Devices {
+		errs = append(errs, dc.validate(key))
+	}
+	for i, h := range c.Exec {
+		errs = append(errs, h.validate(i))
+	}
+	names := make(map[string]bool)
+	for i, t := range c.Tasks {
+		errs = append(errs, t.validate(i))
+		if names[t.Name] {
+			errs = append(errs, fmt.Errorf("tasks[%d]: duplicate name %q", i, t.Name))
+		}
+		names[t.Name] = true
+	}
+	return errors.Join(errs...)
+}
+
+// Apply a configuration, see LoadConfig. Zero fields keep their defaults.
+func WithConfig(c *Config) Option {
+	return func(m *Manager) {
+		m.mu.Lock()
+		m.applyLocked(c)
+		m.mu.Unlock()
+		for _, t := range c.Tasks {
+			m.SetTaskEnabled(t.Name, !t.Disabled)
+		}
+	}
+}
+
+// applyLocked takes over a configuration. m.mu must be held.
+func (m *Manager) applyLocked(c *Config) {
+	for _, f := range c.Filters {
+		m.filters[filter{vid: f.Vid, pid: f.Pid}] = true
+	}
+	if c.SettleDelay > 0 {
+		m.settle = time.Duration(c.SettleDelay)
+	}
+	if c.SMSPoll > 0 {
+		m.smsPoll = time.Duration(c.SMSPoll)
+	}
+	if c.SMSDelete {
+		m.smsDelete = true
+	}
+	m.cfg = *c
+	m.cfg.Devices = make(map[string]DeviceConfig, len(c.Devices)+len(m.setDevices))
+	for key, dc := range c.Devices {
+		m.cfg.Devices[strings.ToLower(key)] = dc
+	}
+	for key, dc := range m.setDevices {
+		m.cfg.Devices[key] = dc
+	}
+	m.cfg.Aliases = make(map[string]string, len(c.Aliases))
+	for k, v := range c.Aliases {
+		m.cfg.Aliases[k] = v
+	}
+}
+
+// apnFor returns the configured APN for a SIM.
+func (m *Manager) apnFor(iccid string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if apn, ok := m.cfg.APNs[iccid]; ok {
+		return apn
+	}
+	return m.cfg.APN
+}
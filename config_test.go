@@ -0,0 +1,98 @@
+package modem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want []string // substrings of the error, none for a valid config
+	}{
+		{"empty", Config{}, nil},
+		{"valid", Config{
+			Filters:      []Filter{{Vid: "12d1", Pid: "1001"}},
+			SettleDelay:  Duration(time.Second),
+			PINs:         map[string]string{"8946000000000000001": "1234"},
+			APNs:         map[string]string{"8946000000000000001": "internet"},
+			InitCommands: []string{"AT+CMEE=2", "ATE0"},
+			Serial:       Line{Parity: "none", StopBits: 1, FlowControl: "rtscts"},
+			Devices:      map[string]DeviceConfig{"12d1:1001": {Baud: 115200, CommandInterface: "02"}},
+			Exec:         []ExecHook{{On: []string{"add"}, Run: "true"}},
+			Tasks:        []Task{{Name: "ping", Action: TaskKeepalive, Every: Duration(time.Minute)}},
+			Commands:     CommandPolicy{Allow: []string{"AT+CSQ"}, Deny: []string{"AT+CFUN"}},
+		}, nil},
+		{"filter", Config{Filters: []Filter{{Vid: "12d", Pid: "1001"}}}, []string{"filters[0]"}},
+		{"negative durations", Config{SettleDelay: -1, SMSPoll: -1}, []string{"settle_delay", "sms_poll"}},
+		{"pins", Config{PINs: map[string]string{"89": "12"}}, []string{`invalid ICCID "89"`, "4 to 8 digits"}},
+		{"apns", Config{APNs: map[string]string{"x": "internet"}}, []string{`apns: invalid ICCID "x"`}},
+		{"init commands", Config{InitCommands: []string{"CMEE=2", "AT\rAT+CFUN=0"}}, []string{"init_commands[0]", "init_commands[1]"}},
+		{"serial", Config{Serial: Line{Parity: "mark", StopBits: 3, FlowControl: "xonxoff"}}, []string{"parity", "stop_bits", "flow_control"}},
+		{"commands", Config{Commands: CommandPolicy{Allow: []string{"CSQ"}, Deny: []string{"CFUN"}}}, []string{"commands.allow[0]", "commands.deny[0]"}},
+		{"devices", Config{Devices: map[string]DeviceConfig{
+			"12d1":      {},
+			"12d1:1001": {SettleDelay: -1, Baud: 1234, CommandInterface: "2", InitCommands: []string{"X"}},
+		}}, []string{`"12d1" is not vid:pid`, "settle_delay", "unsupported baud rate 1234", "command_interface", "init_commands[0]"}},
+		{"exec", Config{Exec: []ExecHook{{}, {On: []string{"boot"}, Run: "true"}, {Run: "true", Timeout: -1}}}, []string{"exec[0]: run is empty", `exec[1]: unknown event "boot"`, "exec[2]: timeout"}},
+		{"tasks", Config{Tasks: []Task{
+			{Name: "a", Action: "reboot", Every: Duration(time.Minute)},
+			{Name: "a", Action: TaskSignal, Every: 0, Jitter: -1},
+		}}, []string{"tasks[0]: \"reboot\"", "tasks[1]: every", "tasks[1]: jitter", `tasks[1]: duplicate name "a"`}},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.Validate()
+		if len(tt.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: no error, want %q", tt.name, tt.want)
+			continue
+		}
+		for _, w := range tt.want {
+			if !strings.Contains(err.Error(), w) {
+				t.Errorf("%s: error %q does not mention %q", tt.name, err, w)
+			}
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string // substring, empty for no error
+	}{
+		{"ok.yaml", "filters:\n  - vid: 12d1\n    pid: \"1001\"\nsettle_delay: 2s\n", ""},
+		{"ok.json", `{"filters": [{"vid": "12d1", "pid": "1001"}], "settle_delay": "2s"}`, ""},
+		{"empty.yaml", "", ""},
+		{"unknown.yaml", "settle: 2s\n", "field settle not found"},
+		{"nested.yaml", "devices:\n  12d1:1001:\n    baudrate: 9600\n", "field baudrate not found"},
+		{"unknown.json", `{"settle": "2s"}`, `unknown field "settle"`},
+		{"nested.json", `{"serial": {"parity": "none", "stopbits": 1}}`, `unknown field "stopbits"`},
+		{"invalid.json", `{"settle_delay": "-2s"}`, "settle_delay must not be negative"},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(path)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+		case tt.err == "" && tt.data != "" && (len(cfg.Filters) != 1 || cfg.SettleDelay != Duration(2*time.Second)):
+			t.Errorf("%s: loaded %+v", tt.name, cfg)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package modem
+
+import "strings"
+
+// Control channel roles by the driver of their USB interface.
+var controlDrivers = map[string]PortRole{
+	"cdc_mbim": PortMBIM,
+	"qmi_wwan": PortQMI,
+}
+
+// control adopts the MBIM or QMI channel of a modem as one of its ports,
+// so modules exposing both a control channel and AT ports stay a single
+// modem. The channel is not used by the manager.
+func (m *Manager) control(key string, d Modem, dev Device, r RejectedDevice) {
+	role, ok := controlDrivers[dev.InterfaceAttr("driver")]
+	if !ok || !strings.HasPrefix(dev.SysName(), "cdc-wdm") {
+		r.Reason = RejectSubsystem
+		m.reject(dev, r)
+		return
+	}
+	m.log.Debug("control channel adopted", "usb", key, "node", dev.DevNode(), "role", role)
+	m.store(key, d)
+	m.setPort(key, Port{Node: dev.DevNode(), Role: role, Interface: dev.InterfaceAttr("bInterfaceNumber")})
+}
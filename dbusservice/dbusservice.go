@@ -0,0 +1,203 @@
+/*
+Package dbusservice publishes the modems of a modem.Manager on DBus, so
+desktop and system components can observe them.
+
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return err
+	}
+	m := modem.New(modem.WithPlugins(dbusservice.Plugin(conn)))
+
+The service owns the name com.github.ausrasul.Modem. Each ready modem is
+an object /com/github/ausrasul/Modem/<IMEI> with the read-only
+properties Imei, Tty, Net of interface com.github.ausrasul.Modem1.Modem.
+The root object /com/github/ausrasul/Modem implements
+com.github.ausrasul.Modem1 with the method List and the signals Added,
+Removed and SmsReceived. The last argument of each signal is the Seq of
+the manager event it reports, zero for the modems exported at start.
+*/
+package dbusservice
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ausrasul/modem"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	Name       = "com.github.ausrasul.Modem"
+	RootPath   = dbus.ObjectPath("/com/github/ausrasul/Modem")
+	RootIface  = "com.github.ausrasul.Modem1"
+	ModemIface = RootIface + ".Modem"
+)
+
+var rootIntrospect = introspect.Interface{
+	Name: RootIface,
+	Methods: []introspect.Method{{
+		Name: "List",
+		Args: []introspect.Arg{{Name: "modems", Type: "ao", Direction: "out"}},
+	}},
+	Signals: []introspect.Signal{
+		{Name: "Added", Args: []introspect.Arg{{Name: "modem", Type: "o"}, {Name: "seq", Type: "t"}}},
+		{Name: "Removed", Args: []introspect.Arg{{Name: "modem", Type: "o"}, {Name: "seq", Type: "t"}}},
+		{Name: "SmsReceived", Args: []introspect.Arg{
+			{Name: "modem", Type: "o"},
+			{Name: "sender", Type: "s"},
+			{Name: "text", Type: "s"},
+			{Name: "seq", Type: "t"},
+		}},
+	},
+}
+
+type service struct {
+	conn   *dbus.Conn
+	m      *modem.Manager
+	events <-chan modem.ModemEvent
+	done   chan struct{}
+
+	mu      sync.Mutex
+	objects map[dbus.ObjectPath]*prop.Properties
+}
+
+// Plugin exports the manager's modems on conn while it is monitoring.
+func Plugin(conn *dbus.Conn) modem.Plugin {
+	return &service{conn: conn}
+}
+
+func (s *service) Start(m *modem.Manager) error {
+	reply, err := s.conn.RequestName(Name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return errors.New("DBus name " + Name + " is already taken")
+	}
+	s.m = m
+	s.objects = make(map[dbus.ObjectPath]*prop.Properties)
+	s.done = make(chan struct{})
+	root := &rootObject{s: s}
+	if err := s.conn.Export(root, RootPath, RootIface); err != nil {
+		return err
+	}
+	node := &introspect.Node{
+		Name:       string(RootPath),
+		Interfaces: []introspect.Interface{introspect.IntrospectData, rootIntrospect},
+	}
+	s.conn.Export(introspect.NewIntrospectable(node), RootPath, "org.freedesktop.DBus.Introspectable")
+
+	s.events = m.Events()
+	for _, d := range m.List() {
+		s.add(d, 0)
+	}
+	go s.run()
+	return nil
+}
+
+func (s *service) Stop() error {
+	s.m.Unsubscribe(s.events)
+	<-s.done
+	s.mu.Lock()
+	for path := range s.objects {
+		s.unexport(path)
+	}
+	s.mu.Unlock()
+	s.conn.Export(nil, RootPath, RootIface)
+	s.conn.Export(nil, RootPath, "org.freedesktop.DBus.Introspectable")
+	_, err := s.conn.ReleaseName(Name)
+	return err
+}
+
+func (s *service) run() {
+	defer close(s.done)
+	for ev := range s.events {
+		switch ev.Type {
+		case modem.EventAdd, modem.EventUpdate:
+			s.add(ev.Modem, ev.Seq)
+		case modem.EventRemove:
+			s.remove(ev.Modem, ev.Seq)
+		case modem.EventSMS:
+			s.conn.Emit(RootPath, RootIface+".SmsReceived", objectPath(ev.Modem), ev.SMS.Sender, ev.SMS.Text, ev.Seq)
+		}
+	}
+}
+
+// objectPath names a modem's object after its IMEI.
+func objectPath(d modem.Modem) dbus.ObjectPath {
+	return RootPath + "/" + dbus.ObjectPath(d.Imei)
+}
+
+// add exports a modem object, or updates its properties if it exists.
+// Modems without an IMEI are not exported. seq is passed in Added.
+func (s *service) add(d modem.Modem, seq uint64) {
+	if d.Imei == "" {
+		return
+	}
+	path := objectPath(d)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if props, ok := s.objects[path]; ok {
+		props.SetMust(ModemIface, "Tty", d.Tty)
+		props.SetMust(ModemIface, "Net", d.Net)
+		return
+	}
+	props, err := prop.Export(s.conn, path, prop.Map{
+		ModemIface: {
+			"Imei": {Value: d.Imei, Emit: prop.EmitTrue},
+			"Tty":  {Value: d.Tty, Emit: prop.EmitTrue},
+			"Net":  {Value: d.Net, Emit: prop.EmitTrue},
+		},
+	})
+	if err != nil {
+		return
+	}
+	node := &introspect.Node{
+		Name: string(path),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: ModemIface, Properties: props.Introspection(ModemIface)},
+		},
+	}
+	s.conn.Export(introspect.NewIntrospectable(node), path, "org.freedesktop.DBus.Introspectable")
+	s.objects[path] = props
+	s.conn.Emit(RootPath, RootIface+".Added", path, seq)
+}
+
+func (s *service) remove(d modem.Modem, seq uint64) {
+	path := objectPath(d)
+	s.mu.Lock()
+	_, ok := s.objects[path]
+	if ok {
+		s.unexport(path)
+	}
+	s.mu.Unlock()
+	if ok {
+		s.conn.Emit(RootPath, RootIface+".Removed", path, seq)
+	}
+}
+
+// unexport drops a modem object. s.mu must be held.
+func (s *service) unexport(path dbus.ObjectPath) {
+	s.conn.Export(nil, path, "org.freedesktop.DBus.Properties")
+	s.conn.Export(nil, path, "org.freedesktop.DBus.Introspectable")
+	delete(s.objects, path)
+}
+
+// rootObject implements the methods of RootIface.
+type rootObject struct {
+	s *service
+}
+
+func (r *rootObject) List() ([]dbus.ObjectPath, *dbus.Error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	paths := make([]dbus.ObjectPath, 0, len(r.s.objects))
+	for path := range r.s.objects {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
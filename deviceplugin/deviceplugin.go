@@ -0,0 +1,263 @@
+/*
+Package deviceplugin advertises the modems of a modem.Manager to the
+kubelet as allocatable resources, so pods can claim a specific modem.
+
+	m := modem.New(modem.WithPlugins(deviceplugin.Plugin(deviceplugin.Config{})))
+
+Each ready modem is a device whose ID is its IMEI. A container that is
+allocated a modem gets its data port, the second AT port, as a device
+node at the same path as on the host. The command port stays with the
+manager, so modems without a data port are reported unhealthy and are
+not allocated. A modem turns healthy when its data port is classified,
+which the manager announces with an update event. The environment variables MODEM_IMEI, MODEM_TTY (the data
+port) and MODEM_NET hold one comma separated value per allocated modem,
+in IMEI order. The network interface itself stays in the host namespace.
+
+The plugin must run on the node with access to the kubelet's
+device-plugins directory. It registers again whenever the kubelet
+restarts and removes its socket.
+*/
+package deviceplugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// DefaultResource is the resource name used when none is configured.
+const DefaultResource = "modem.ausrasul.github.com/usb"
+
+// How often the socket is checked for a kubelet restart.
+const socketCheck = 5 * time.Second
+
+// Config of the device plugin. Zero fields take their defaults.
+type Config struct {
+	Resource string // resource name requested by pods, default DefaultResource
+	Dir      string // kubelet device-plugins directory, default pluginapi.DevicePluginPath
+	Socket   string // socket file name in Dir, default modem.sock
+}
+
+type plugin struct {
+	pluginapi.UnimplementedDevicePluginServer
+	cfg Config
+	m   *modem.Manager
+
+	events <-chan modem.ModemEvent
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	srv     *grpc.Server
+	changed chan struct{} // closed and replaced on every modem change
+}
+
+// Plugin serves the kubelet device plugin API while the manager is monitoring.
+func Plugin(cfg Config) modem.Plugin {
+	if cfg.Resource == "" {
+		cfg.Resource = DefaultResource
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = pluginapi.DevicePluginPath
+	}
+	if cfg.Socket == "" {
+		cfg.Socket = "modem.sock"
+	}
+	return &plugin{cfg: cfg}
+}
+
+func (p *plugin) socketPath() string {
+	return filepath.Join(p.cfg.Dir, p.cfg.Socket)
+}
+
+func (p *plugin) Start(m *modem.Manager) error {
+	p.m = m
+	p.stop = make(chan struct{})
+	p.changed = make(chan struct{})
+	if err := p.serve(); err != nil {
+		return err
+	}
+	p.events = m.Events()
+	p.wg.Add(2)
+	go p.watchModems()
+	go p.watchSocket()
+	return nil
+}
+
+func (p *plugin) Stop() error {
+	close(p.stop)
+	p.m.Unsubscribe(p.events)
+	p.wg.Wait()
+	p.mu.Lock()
+	p.srv.Stop()
+	p.mu.Unlock()
+	os.Remove(p.socketPath())
+	return nil
+}
+
+// serve starts the gRPC server on a fresh socket and registers with the kubelet.
+func (p *plugin) serve() error {
+	path := p.socketPath()
+	os.Remove(path)
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer()
+	pluginapi.RegisterDevicePluginServer(srv, p)
+	go srv.Serve(lis)
+
+	p.mu.Lock()
+	old := p.srv
+	p.srv = srv
+	p.mu.Unlock()
+	if old != nil {
+		old.Stop()
+	}
+	return p.register()
+}
+
+func (p *plugin) register() error {
+	conn, err := grpc.NewClient("unix://"+filepath.Join(p.cfg.Dir, filepath.Base(pluginapi.KubeletSocket)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = pluginapi.NewRegistrationClient(conn).Register(ctx, &pluginapi.RegisterRequest{
+		Version:      pluginapi.Version,
+		Endpoint:     p.cfg.Socket,
+		ResourceName: p.cfg.Resource,
+	})
+	return err
+}
+
+// watchModems wakes up ListAndWatch streams on every modem change.
+func (p *plugin) watchModems() {
+	defer p.wg.Done()
+	for range p.events {
+		p.mu.Lock()
+		close(p.changed)
+		p.changed = make(chan struct{})
+		p.mu.Unlock()
+	}
+}
+
+// watchSocket serves and registers again after the kubelet removed the
+// socket, which it does when it restarts.
+func (p *plugin) watchSocket() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(socketCheck):
+		}
+		if _, err := os.Stat(p.socketPath()); errors.Is(err, os.ErrNotExist) {
+			p.serve()
+		}
+	}
+}
+
+// dataPort returns the node of the data port of a modem.
+func dataPort(d modem.Modem) string {
+	for _, port := range d.Ports {
+		if port.Role == modem.PortData {
+			return port.Node
+		}
+	}
+	return ""
+}
+
+// devices lists the ready modems, sorted by IMEI. Those without a data
+// port are unhealthy.
+func (p *plugin) devices() []*pluginapi.Device {
+	var devs []*pluginapi.Device
+	for _, d := range p.m.List() {
+		health := pluginapi.Healthy
+		if dataPort(d) == "" {
+			health = pluginapi.Unhealthy
+		}
+		devs = append(devs, &pluginapi.Device{ID: d.Imei, Health: health})
+	}
+	sort.Slice(devs, func(i, j int) bool { return devs[i].ID < devs[j].ID })
+	return devs
+}
+
+func (p *plugin) GetDevicePluginOptions(context.Context, *pluginapi.Empty) (*pluginapi.DevicePluginOptions, error) {
+	return &pluginapi.DevicePluginOptions{}, nil
+}
+
+func (p *plugin) ListAndWatch(_ *pluginapi.Empty, stream grpc.ServerStreamingServer[pluginapi.ListAndWatchResponse]) error {
+	for {
+		p.mu.Lock()
+		changed := p.changed
+		p.mu.Unlock()
+		if err := stream.Send(&pluginapi.ListAndWatchResponse{Devices: p.devices()}); err != nil {
+			return err
+		}
+		select {
+		case <-changed:
+		case <-p.stop:
+			return nil
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (p *plugin) Allocate(ctx context.Context, req *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
+	byImei := make(map[string]modem.Modem)
+	for _, d := range p.m.List() {
+		byImei[d.Imei] = d
+	}
+	resp := &pluginapi.AllocateResponse{}
+	for _, creq := range req.ContainerRequests {
+		cresp := &pluginapi.ContainerAllocateResponse{}
+		ids := append([]string(nil), creq.DevicesIds...)
+		sort.Strings(ids)
+		var imeis, ttys, nets []string
+		for _, id := range ids {
+			d, ok := byImei[id]
+			if !ok {
+				return nil, errors.New("Modem " + id + " is gone")
+			}
+			tty := dataPort(d)
+			if tty == "" {
+				return nil, errors.New("Modem " + id + " has no data port")
+			}
+			cresp.Devices = append(cresp.Devices, &pluginapi.DeviceSpec{
+				ContainerPath: tty,
+				HostPath:      tty,
+				Permissions:   "rw",
+			})
+			imeis = append(imeis, d.Imei)
+			ttys = append(ttys, tty)
+			nets = append(nets, d.Net)
+		}
+		cresp.Envs = map[string]string{
+			"MODEM_IMEI": strings.Join(imeis, ","),
+			"MODEM_TTY":  strings.Join(ttys, ","),
+			"MODEM_NET":  strings.Join(nets, ","),
+		}
+		resp.ContainerResponses = append(resp.ContainerResponses, cresp)
+	}
+	return resp, nil
+}
+
+func (p *plugin) PreStartContainer(context.Context, *pluginapi.PreStartContainerRequest) (*pluginapi.PreStartContainerResponse, error) {
+	return &pluginapi.PreStartContainerResponse{}, nil
+}
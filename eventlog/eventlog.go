@@ -0,0 +1,188 @@
+/*
+Package eventlog records modem lifecycle events of a modem.Manager in the
+system log, so the history of each modem survives without a separate
+collector.
+
+	m := modem.New(modem.WithPlugins(eventlog.Journal()))
+
+Journal writes to journald with a MESSAGE_ID per event type and the
+fields MODEM_EVENT, MODEM_IMEI, MODEM_ICCID, MODEM_TTY and MODEM_NET,
+MODEM_ALIAS for labelled modems and MODEM_JAMMING for jamming events:
+
+	journalctl MESSAGE_ID=08e92c16fee1496e966ce3bb77000422 MODEM_IMEI=490154203237518
+
+Syslog writes the same records through log/syslog, with the fields as
+key=value text sorted by key.
+For SMS events only the sender is recorded, never the text.
+*/
+package eventlog
+
+import (
+	"errors"
+	"fmt"
+	"log/syslog"
+	"maps"
+	"slices"
+
+	"github.com/ausrasul/modem"
+	"github.com/coreos/go-systemd/v22/journal"
+)
+
+// Journal MESSAGE_IDs of the records, one per event type.
+const (
+	MessageAdd        = "08e92c16fee1496e966ce3bb77000422"
+	MessageUpdate     = "c027d7471ed8440eb2ba3130955958f9"
+	MessageRemove     = "02c474a181ae4d3b829a53c6f55275b6"
+	MessageSMS        = "1895466ac55f408f8d351efdc84b3d0f"
+	MessageConnect    = "612a4c7360384d74a2ae7df05386b4d1"
+	MessageDisconnect = "7b3b0140de1b4119ad69f03a17020980"
+	MessageReopen     = "4cccebb159cd4b9bb5d39568dbddf794"
+	MessageGaveUp     = "1279ad0bb2a04618bbf809c51f31afb5"
+	MessageJamming    = "0cc917661d8a4377a28a267e4fde4af8"
+)
+
+func messageID(t modem.EventType) string {
+	switch t {
+	case modem.EventUpdate:
+		return MessageUpdate
+	case modem.EventRemove:
+		return MessageRemove
+	case modem.EventSMS:
+		return MessageSMS
+	case modem.EventConnect:
+		return MessageConnect
+	case modem.EventDisconnect:
+		return MessageDisconnect
+	case modem.EventReopen:
+		return MessageReopen
+	case modem.EventGaveUp:
+		return MessageGaveUp
+	case modem.EventJamming:
+		return MessageJamming
+	}
+	return MessageAdd
+}
+
+// message is the human readable line of a record.
+func message(ev modem.ModemEvent) string {
+	switch ev.Type {
+	case modem.EventSMS:
+		return fmt.Sprintf("Modem %s received an SMS from %s", ev.Modem.Imei, ev.SMS.Sender)
+	case modem.EventRemove:
+		return fmt.Sprintf("Modem %s removed", ev.Modem.Imei)
+	case modem.EventConnect, modem.EventDisconnect:
+		return fmt.Sprintf("Modem %s data %sed on %s", ev.Modem.Imei, ev.Type, ev.Modem.Net)
+	case modem.EventReopen:
+		return fmt.Sprintf("Modem %s port %s failed and was reopened", ev.Modem.Imei, ev.Modem.Tty)
+	case modem.EventGaveUp:
+		return fmt.Sprintf("Modem probing gave up on %s, no port answered", ev.Modem.Tty)
+	case modem.EventJamming:
+		if ev.Modem.Jamming == modem.JammingDetected {
+			return fmt.Sprintf("Modem %s reports jamming", ev.Modem.Imei)
+		}
+		return fmt.Sprintf("Modem %s jamming state is %s", ev.Modem.Imei, ev.Modem.Jamming)
+	}
+	return fmt.Sprintf("Modem %s %s on %s", ev.Modem.Imei, ev.Type, ev.Modem.Tty)
+}
+
+// fields are the structured values of a record.
+func fields(ev modem.ModemEvent) map[string]string {
+	f := map[string]string{
+		"MESSAGE_ID":  messageID(ev.Type),
+		"MODEM_EVENT": ev.Type.String(),
+		"MODEM_IMEI":  ev.Modem.Imei,
+		"MODEM_ICCID": ev.Modem.Iccid,
+		"MODEM_TTY":   ev.Modem.Tty,
+		"MODEM_NET":   ev.Modem.Net,
+	}
+	if ev.Modem.Alias != "" {
+		f["MODEM_ALIAS"] = ev.Modem.Alias
+	}
+	if ev.Type == modem.EventSMS {
+		f["SMS_SENDER"] = ev.SMS.Sender
+	}
+	if ev.Type == modem.EventJamming {
+		f["MODEM_JAMMING"] = ev.Modem.Jamming.String()
+	}
+	return f
+}
+
+// syslogLine is the message of a record followed by its non-empty
+// fields, sorted by key.
+func syslogLine(ev modem.ModemEvent) string {
+	f := fields(ev)
+	line := message(ev)
+	for _, k := range slices.Sorted(maps.Keys(f)) {
+		if v := f[k]; v != "" {
+			line += fmt.Sprintf(" %s=%q", k, v)
+		}
+	}
+	return line
+}
+
+// sink runs write for every event while the manager is monitoring.
+type sink struct {
+	open   func() error
+	write  func(ev modem.ModemEvent)
+	close  func() error
+	m      *modem.Manager
+	events <-chan modem.ModemEvent
+	done   chan struct{}
+}
+
+func (s *sink) Start(m *modem.Manager) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+	s.m = m
+	s.events = m.Events()
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		for ev := range s.events {
+			// Modems removed before they answered have neither.
+			if ev.Modem.Imei != "" || ev.Modem.Tty != "" {
+				s.write(ev)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *sink) Stop() error {
+	s.m.Unsubscribe(s.events)
+	<-s.done
+	return s.close()
+}
+
+// Journal records events in journald.
+func Journal() modem.Plugin {
+	return &sink{
+		open: func() error {
+			if !journal.Enabled() {
+				return errors.New("journald is not available")
+			}
+			return nil
+		},
+		write: func(ev modem.ModemEvent) {
+			journal.Send(message(ev), journal.PriInfo, fields(ev))
+		},
+		close: func() error { return nil },
+	}
+}
+
+// Syslog records events through syslog.Dial(network, raddr, LOG_INFO|LOG_DAEMON, tag).
+// An empty network and raddr log to the local syslog daemon.
+func Syslog(network string, raddr string, tag string) modem.Plugin {
+	var w *syslog.Writer
+	return &sink{
+		open: func() (err error) {
+			w, err = syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+			return err
+		},
+		write: func(ev modem.ModemEvent) {
+			w.Info(syslogLine(ev))
+		},
+		close: func() error { return w.Close() },
+	}
+}
@@ -0,0 +1,32 @@
+package eventlog
+
+import (
+	"testing"
+
+	"github.com/ausrasul/modem"
+)
+
+func TestSyslogLine(t *testing.T) {
+	tests := []struct {
+		ev   modem.ModemEvent
+		want string
+	}{
+		{
+			modem.ModemEvent{Type: modem.EventAdd, Modem: modem.Modem{Imei: "490154203237518", Tty: "/dev/ttyUSB2", Alias: "roof"}},
+			`Modem 490154203237518 add on /dev/ttyUSB2 MESSAGE_ID="` + MessageAdd + `" MODEM_ALIAS="roof" MODEM_EVENT="add" MODEM_IMEI="490154203237518" MODEM_TTY="/dev/ttyUSB2"`,
+		},
+		{
+			modem.ModemEvent{Type: modem.EventJamming, Modem: modem.Modem{Imei: "490154203237518", Jamming: modem.JammingDetected}},
+			`Modem 490154203237518 reports jamming MESSAGE_ID="` + MessageJamming + `" MODEM_EVENT="jamming" MODEM_IMEI="490154203237518" MODEM_JAMMING="jammed"`,
+		},
+		{
+			modem.ModemEvent{Type: modem.EventGaveUp, Modem: modem.Modem{Tty: "/dev/ttyUSB0"}},
+			`Modem probing gave up on /dev/ttyUSB0, no port answered MESSAGE_ID="` + MessageGaveUp + `" MODEM_EVENT="gaveup" MODEM_TTY="/dev/ttyUSB0"`,
+		},
+	}
+	for _, tt := range tests {
+		if got := syslogLine(tt.ev); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.ev.Type, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package modem
+
+import "iter"
+
+// Default size of each subscriber channel returned by Events.
+const eventBuffer = 16
+
+// Buffer up to n events for each subscriber of Events. Defaults to 16.
+func WithEventBuffer(n int) Option {
+	return func(m *Manager) {
+		if n < 1 {
+			n = 1
+		}
+		m.eventBuffer = n
+	}
+}
+
+// Kind of modem lifecycle event.
+type EventType int
+
+const (
+	EventAdd    EventType = iota
+	EventUpdate           // a ready modem changed, e.g. its ports, alias or tty after a replug
+	EventRemove
+	EventSMS        // a text message was received, see ModemEvent.SMS
+	EventConnect    // Connect activated a data connection
+	EventDisconnect // Disconnect ended it
+	EventReopen     // the command port failed and was reopened, see ReopenTimeout
+	EventGaveUp     // no port answered the probe, see WithProbeBackoff; Tty is the port tried
+	EventJamming    // the modem reported a new Modem.Jamming state
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdd:
+		return "add"
+	case EventUpdate:
+		return "update"
+	case EventRemove:
+		return "remove"
+	case EventSMS:
+		return "sms"
+	case EventConnect:
+		return "connect"
+	case EventDisconnect:
+		return "disconnect"
+	case EventReopen:
+		return "reopen"
+	case EventGaveUp:
+		return "gaveup"
+	case EventJamming:
+		return "jamming"
+	}
+	return "unknown"
+}
+
+// Modem lifecycle event, delivered on the channels returned by Events.
+type ModemEvent struct {
+	Type  EventType
+	Modem Modem
+	SMS   SMS // only set for EventSMS
+	// Position of the event among all events of the manager, from 1.
+	Seq uint64
+}
+
+// Returns a channel receiving every modem event. Each call makes a new
+// subscription; all of them are closed when the monitor stops.
+//
+// Events are numbered and queued as they happen, and delivered one at a
+// time in the order of their Seq by a single goroutine, which also runs
+// the AddHandler functions and the OnEvent hook. The add, update and
+// remove events of a modem are numbered together with the change of its
+// state, so they arrive in the order the changes happened; other events,
+// such as EventConnect, when the operation they report finished.
+//
+// The channel buffers the events set with WithEventBuffer. A subscriber
+// whose buffer is full misses the new events instead of stalling the
+// others, and sees a gap in Seq once it catches up.
+func (m *Manager) Events() <-chan ModemEvent {
+	ch := make(chan ModemEvent, m.eventBuffer)
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, ch)
+	m.mu.Unlock()
+	return ch
+}
+
+// End a subscription made through Events and close its channel.
+func (m *Manager) Unsubscribe(events <-chan ModemEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, ch := range m.subscribers {
+		if ch == events {
+			close(ch)
+			m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns the modem events as a sequence for range loops. Each range
+// subscribes through Events when it starts and unsubscribes when the loop
+// ends, either by break or because the monitor stopped.
+func (m *Manager) EventSeq() iter.Seq[ModemEvent] {
+	return func(yield func(ModemEvent) bool) {
+		events := m.Events()
+		defer m.Unsubscribe(events)
+		for ev := range events {
+			if !yield(ev) {
+				return
+			}
+		}
+	}
+}
+
+// queued is an event waiting to be delivered.
+type queued struct {
+	ev     ModemEvent
+	action string    // udev action of add, update and remove events, for the handlers
+	done   chan bool // receives whether the event was received, if not nil
+}
+
+// queueLocked numbers an event and queues it for delivery, starting a
+// dispatcher if none runs. m.mu must be held.
+func (m *Manager) queueLocked(q queued) {
+	m.seq++
+	q.ev.Seq = m.seq
+	m.outbox = append(m.outbox, q)
+	if !m.dispatching {
+		m.dispatching = true
+		go m.dispatch()
+	}
+}
+
+// dispatch delivers the queued events in order until none is left.
+func (m *Manager) dispatch() {
+	for {
+		m.mu.Lock()
+		if len(m.outbox) == 0 {
+			m.dispatching = false
+			m.outbox = nil
+			m.drained.Broadcast()
+			m.mu.Unlock()
+			return
+		}
+		q := m.outbox[0]
+		m.outbox = m.outbox[1:]
+		m.mu.Unlock()
+		m.deliver(q)
+	}
+}
+
+// deliver runs the handlers of an event, then passes it to the Events
+// subscribers and the OnEvent hook.
+func (m *Manager) deliver(q queued) {
+	d := q.ev.Modem
+	switch q.action {
+	case "":
+	case "remove":
+		m.remember(q.action, d)
+		m.handleRemove(d)
+	case "update":
+		m.remember(q.action, d)
+		m.handleUpdate(d)
+	default:
+		m.remember(q.action, d)
+		m.handleAdd(d)
+	}
+	sent, missed := m.broadcast(q.ev)
+	m.hooks.event(q.ev)
+	if q.done != nil {
+		q.done <- missed == 0 && (sent > 0 || m.hooks.OnEvent != nil)
+	}
+}
+
+// broadcast delivers an event to every subscriber without blocking, and
+// returns how many subscribers got it and how many missed it.
+func (m *Manager) broadcast(ev ModemEvent) (sent int, missed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- ev:
+			sent++
+		default:
+			missed++
+		}
+	}
+	return sent, missed
+}
+
+// flush waits until the queued events are delivered.
+func (m *Manager) flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.dispatching {
+		m.drained.Wait()
+	}
+}
+
+// closeSubscribers ends every subscription made through Events.
+func (m *Manager) closeSubscribers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = nil
+}
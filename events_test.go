@@ -0,0 +1,133 @@
+package modem
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestEventsInOrder(t *testing.T) {
+	usb1, tty1 := usbModem("1", "490154203237518")
+	usb2, tty2 := usbModem("2", "352099001761481")
+	m := New(
+		WithBackend(fakeBackend{devices: []Device{tty1, tty2, unplug(usb1), unplug(usb2)}}),
+		WithEventBuffer(64),
+		WithReplugGrace(0),
+	)
+	m.AddFilter("12d1", "1001")
+	var mu sync.Mutex
+	var handled []string
+	record := func(what string) func(Modem) {
+		return func(d Modem) {
+			mu.Lock()
+			handled = append(handled, what+" "+d.Imei)
+			mu.Unlock()
+		}
+	}
+	m.AddHandler(record("add"), record("update"), record("remove"))
+	events := m.Events()
+	if err := m.Monitor(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	var last uint64
+	for ev := range events {
+		if ev.Seq != last+1 {
+			t.Errorf("event %s has Seq %d after %d", ev.Type, ev.Seq, last)
+		}
+		last = ev.Seq
+		got = append(got, ev.Type.String()+" "+ev.Modem.Imei)
+		if len(got) == 4 {
+			m.StopMonitor()
+		}
+	}
+	want := []string{"add 490154203237518", "add 352099001761481", "remove 490154203237518", "remove 352099001761481"}
+	if len(got) != len(want) {
+		t.Fatalf("events %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] || handled[i] != want[i] {
+			t.Errorf("event %d: got %q, handled %q, want %q", i, got[i], handled[i], want[i])
+		}
+	}
+}
+
+func TestEventsFromManyGoroutines(t *testing.T) {
+	m := New(WithEventBuffer(1000))
+	events := m.Events()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				m.emit(ModemEvent{Type: EventSMS})
+			}
+		}()
+	}
+	wg.Wait()
+	m.flush()
+	m.closeSubscribers()
+	var last uint64
+	for ev := range events {
+		if ev.Seq != last+1 {
+			t.Fatalf("Seq %d after %d", ev.Seq, last)
+		}
+		last = ev.Seq
+	}
+	if last != 500 {
+		t.Errorf("received %d events, want 500", last)
+	}
+}
+
+func TestEventBufferDrops(t *testing.T) {
+	m := New(WithEventBuffer(2))
+	events := m.Events()
+	for i := 0; i < 5; i++ {
+		m.emit(ModemEvent{Type: EventSMS})
+	}
+	m.flush()
+	m.closeSubscribers()
+	var seqs []uint64
+	for ev := range events {
+		seqs = append(seqs, ev.Seq)
+	}
+	if len(seqs) != 2 || seqs[0] != 1 || seqs[1] != 2 {
+		t.Errorf("received Seq %v, want [1 2]", seqs)
+	}
+}
+
+func TestMonitorRestartKeepsNewSubscriptions(t *testing.T) {
+	m := New(WithBackend(fakeBackend{}))
+	for i := 0; i < 20; i++ {
+		if err := m.Monitor(); err != nil {
+			t.Fatal(err)
+		}
+		old := m.Events()
+		if err := m.StopMonitor(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case _, ok := <-old:
+			if ok {
+				t.Fatal("event after StopMonitor")
+			}
+		default:
+			t.Fatal("StopMonitor returned before closing the subscriptions")
+		}
+		if err := m.Monitor(); err != nil {
+			t.Fatal(err)
+		}
+		events := m.Events()
+		m.emit(ModemEvent{Type: EventUpdate})
+		if _, ok := <-events; !ok {
+			t.Fatal("the stopped monitor closed a subscription of the new one")
+		}
+		if err := m.StopMonitor(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.StopMonitor(); err == nil {
+		t.Error("StopMonitor of a stopped monitor did not fail")
+	}
+}
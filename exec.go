@@ -0,0 +1,105 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ExecHook runs a shell command for modem events, see Config.Exec. The
+// command gets the event in MODEM_EVENT and the modem in MODEM_IMEI,
+// MODEM_ICCID, MODEM_ALIAS, MODEM_TTY, MODEM_NET, MODEM_VID, MODEM_PID and
+// MODEM_STATE, plus SMS_SENDER for SMS events.
+type ExecHook struct {
+	On      []string `json:"on" yaml:"on"`   // event names, add, update and remove by default
+	Run     string   `json:"run" yaml:"run"` // passed to /bin/sh -c
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+}
+
+var eventNames = map[string]EventType{}
+
+func init() {
+	for t := EventAdd; t.String() != "unknown"; t++ {
+		eventNames[t.String()] = t
+	}
+}
+
+func (h ExecHook) validate(i int) error {
+	if h.Run == "" {
+		return fmt.Errorf("exec[%d]: run is empty", i)
+	}
+	for _, on := range h.On {
+		if _, ok := eventNames[on]; !ok {
+			return fmt.Errorf("exec[%d]: unknown event %q", i, on)
+		}
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("exec[%d]: timeout must not be negative", i)
+	}
+	return nil
+}
+
+func (h ExecHook) matches(t EventType) bool {
+	if len(h.On) == 0 {
+		return t == EventAdd || t == EventUpdate || t == EventRemove
+	}
+	for _, on := range h.On {
+		if eventNames[on] == t {
+			return true
+		}
+	}
+	return false
+}
+
+// runExec runs the configured exec hooks for every event until stop
+// closes. Commands run one at a time, in event order.
+func (m *Manager) runExec(stop chan struct{}, events <-chan ModemEvent, hooks []ExecHook) {
+	defer m.workers.Done()
+	defer m.Unsubscribe(events)
+	for {
+		var ev ModemEvent
+		select {
+		case <-stop:
+			return
+		case ev = <-events:
+		}
+		for _, h := range hooks {
+			if h.matches(ev.Type) {
+				m.execHook(h, ev)
+			}
+		}
+	}
+}
+
+func (m *Manager) execHook(h ExecHook, ev ModemEvent) {
+	timeout := time.Duration(h.Timeout)
+	if timeout == 0 {
+		timeout = time.Second * 30
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", h.Run)
+	d := ev.Modem
+	cmd.Env = append(os.Environ(),
+		"MODEM_EVENT="+ev.Type.String(),
+		"MODEM_IMEI="+d.Imei,
+		"MODEM_ICCID="+d.Iccid,
+		"MODEM_ALIAS="+d.Alias,
+		"MODEM_TTY="+d.Tty,
+		"MODEM_NET="+d.Net,
+		"MODEM_VID="+d.Vid,
+		"MODEM_PID="+d.Pid,
+		"MODEM_STATE="+d.State.String(),
+	)
+	if ev.Type == EventSMS {
+		cmd.Env = append(cmd.Env, "SMS_SENDER="+ev.SMS.Sender)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		m.log.Warn("exec hook failed", "run", h.Run, "event", ev.Type, "imei", d.Imei, "err", err, "output", string(out))
+		return
+	}
+	m.log.Debug("exec hook ran", "run", h.Run, "event", ev.Type, "imei", d.Imei)
+}
@@ -0,0 +1,50 @@
+package modem
+
+import "path/filepath"
+
+// Helpers for the tests of package modem_test, which can use modemtest.
+
+// FakeModem returns the USB device of a modem on port 1-<n> and its
+// command tty, at node, reported with the given IMEI.
+func FakeModem(n string, imei string, node string) (usb Device, tty Device) {
+	u, t := usbModem(n, imei)
+	t.node = node
+	return u, t
+}
+
+// FakeTty returns a tty of the USB device of FakeModem, at node, with the
+// given USB interface attributes. Unlike the tty of FakeModem it is
+// probed or classified.
+func FakeTty(usb Device, node string, iface map[string]string) Device {
+	return &fakeDevice{action: "add", subsystem: "tty", name: filepath.Base(node), node: node, iface: iface, usb: usb.(*fakeDevice)}
+}
+
+// FakeUnplug returns the remove event of a USB device made by FakeModem.
+func FakeUnplug(usb Device) Device {
+	return unplug(usb.(*fakeDevice))
+}
+
+// FakeBackend reports devs, then waits for the monitor to stop.
+func FakeBackend(devs ...Device) Backend {
+	return fakeBackend{devices: devs}
+}
+
+// SetLockDir moves the UUCP lock files to dir.
+func SetLockDir(dir string) {
+	lockDir = dir
+}
+
+func (m *Manager) SweepSMS(d Modem) error {
+	return m.sweepSMS(d)
+}
+
+// ProbeImei probes node as the port of a modem being probed, holding the
+// probe slot.
+func (m *Manager) ProbeImei(node string) (string, error) {
+	m.mu.Lock()
+	m.devices["usb"] = Modem{State: StateProbing}
+	m.mu.Unlock()
+	m.probeSlots <- struct{}{}
+	defer func() { <-m.probeSlots }()
+	return m.probeImei(make(chan struct{}), "usb", node)
+}
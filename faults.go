@@ -0,0 +1,99 @@
+package modem
+
+import (
+	"io"
+	"time"
+)
+
+// Serial operations a fault can be injected into.
+const (
+	OpOpen  = "open"
+	OpWrite = "write"
+	OpRead  = "read"
+)
+
+// Faults injects failures at fixed points of the manager so applications
+// can exercise their recovery logic without broken hardware.
+// Nil fields inject nothing.
+type Faults struct {
+	// SerialError is called before every open, write and read on a port.
+	// A non-nil error is returned in place of the operation.
+	SerialError func(port string, op string) error
+
+	// Truncate may shorten the bytes read from a port before they are parsed.
+	Truncate func(port string, data []byte) []byte
+
+	// EventDelay returns how long a udev event is held before it is handled.
+	EventDelay func(action string, devnode string) time.Duration
+}
+
+// Install fault injection hooks. Must be called before Monitor.
+func (m *Manager) SetFaults(f Faults) {
+	m.faults = f
+}
+
+// dial opens a serial port at baud, routing it through the installed faults.
+func (m *Manager) dial(node string, baud int) (io.ReadWriteCloser, error) {
+	if m.faults.SerialError != nil {
+		if err := m.faults.SerialError(node, OpOpen); err != nil {
+			return nil, err
+		}
+	}
+	m.mu.Lock()
+	line := m.cfg.Serial
+	m.mu.Unlock()
+	sp, err := openSerial(node, baud, line)
+	if err != nil {
+		return nil, err
+	}
+	p := io.ReadWriteCloser(&capturePort{ReadWriteCloser: sp, m: m, node: node})
+	if m.faults.SerialError == nil && m.faults.Truncate == nil {
+		return p, nil
+	}
+	return &faultPort{ReadWriteCloser: p, node: node, f: m.faults}, nil
+}
+
+type faultPort struct {
+	io.ReadWriteCloser
+	node string
+	f    Faults
+}
+
+func (p *faultPort) Write(b []byte) (int, error) {
+	if p.f.SerialError != nil {
+		if err := p.f.SerialError(p.node, OpWrite); err != nil {
+			return 0, err
+		}
+	}
+	return p.ReadWriteCloser.Write(b)
+}
+
+func (p *faultPort) Read(b []byte) (int, error) {
+	if p.f.SerialError != nil {
+		if err := p.f.SerialError(p.node, OpRead); err != nil {
+			return 0, err
+		}
+	}
+	n, err := p.ReadWriteCloser.Read(b)
+	if p.f.Truncate != nil && n > 0 {
+		n = copy(b, p.f.Truncate(p.node, b[:n]))
+	}
+	return n, err
+}
+
+func (p *faultPort) SetReadDeadline(t time.Time) error {
+	if dl, ok := p.ReadWriteCloser.(deadliner); ok {
+		return dl.SetReadDeadline(t)
+	}
+	return nil
+}
+
+// delayEvent holds a udev event for the injected delay, if any.
+func (m *Manager) delayEvent(action, node string) {
+	if m.faults.EventDelay == nil {
+		return
+	}
+	if d := m.faults.EventDelay(action, node); d > 0 {
+		m.clock.Sleep(d)
+	}
+}
@@ -0,0 +1,54 @@
+package modem
+
+import "sort"
+
+// Query is a condition on modems for Find. Any func(Modem) bool will do.
+type Query func(d Modem) bool
+
+// Modems with the given IMEI.
+func ByImei(imei string) Query {
+	return func(d Modem) bool { return d.Imei == imei }
+}
+
+// Modems in the given state.
+func ByState(s State) Query {
+	return func(d Modem) bool { return d.State == s }
+}
+
+// Modems with the given USB vendor id, and product id unless it is empty.
+func ByVendor(vid string, pid string) Query {
+	return func(d Modem) bool { return d.Vid == vid && (pid == "" || d.Pid == pid) }
+}
+
+// Modems with the given alias, see SetAlias.
+func ByAlias(alias string) Query {
+	return func(d Modem) bool { return d.Alias == alias }
+}
+
+// Returns the modems matching all queries, in any state unless a query
+// says otherwise, ordered by IMEI.
+//
+//	m.Find(modem.ByVendor("2c7c", ""), modem.ByState(modem.StateReady))
+func (m *Manager) Find(queries ...Query) []Modem {
+	m.mu.Lock()
+	all := make([]Modem, 0, len(m.devices))
+	for _, d := range m.devices {
+		all = append(all, d)
+	}
+	m.mu.Unlock()
+	var found []Modem
+	for _, d := range all {
+		match := true
+		for _, q := range queries {
+			if !q(d) {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = append(found, d)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Imei < found[j].Imei })
+	return found
+}
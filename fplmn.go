@@ -0,0 +1,102 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ausrasul/modem/atparse"
+)
+
+// SIM elementary file of the forbidden networks, EF_FPLMN.
+const efFPLMN = 0x6f7b
+
+// SIM commands of AT+CRSM.
+const (
+	simReadBinary   = 176
+	simGetResponse  = 192
+	simUpdateBinary = 214
+)
+
+// SIMError is the status a SIM answered an AT+CRSM command with.
+type SIMError struct {
+	SW1, SW2 int
+}
+
+func (e *SIMError) Error() string {
+	return fmt.Sprintf("SIM status %02X %02X", e.SW1, e.SW2)
+}
+
+// Read the networks the SIM of the modem with the given IMEI is forbidden
+// to register on, as MCC and MNC digits, e.g. "24001". Networks rejecting
+// the SIM are added to the list by the modem, and it is not tried again
+// until the list is cleared.
+func (m *Manager) ForbiddenNetworks(imei string) ([]string, error) {
+	size, err := m.fplmnSize(imei)
+	if err != nil {
+		return nil, err
+	}
+	r, err := m.simCommand(imei, fmt.Sprintf("AT+CRSM=%d,%d,0,0,%d", simReadBinary, efFPLMN, size))
+	if err != nil {
+		return nil, err
+	}
+	return atparse.ParsePLMNs(r.Data), nil
+}
+
+// Empty the forbidden network list of the SIM of the modem with the given
+// IMEI. The modem may only try the networks again after it re-registers,
+// e.g. with AT+COPS=0.
+func (m *Manager) ClearForbiddenNetworks(imei string) error {
+	size, err := m.fplmnSize(imei)
+	if err != nil {
+		return err
+	}
+	_, err = m.simCommand(imei, fmt.Sprintf("AT+CRSM=%d,%d,0,0,%d,%q", simUpdateBinary, efFPLMN, size, strings.Repeat("FF", size)))
+	return err
+}
+
+// fplmnSize returns the size of EF_FPLMN from its file control parameters.
+func (m *Manager) fplmnSize(imei string) (int, error) {
+	r, err := m.simCommand(imei, fmt.Sprintf("AT+CRSM=%d,%d,0,0,0", simGetResponse, efFPLMN))
+	if err != nil {
+		return 0, err
+	}
+	size := simFileSize(r.Data)
+	if size <= 0 || size > 255 {
+		return 0, fmt.Errorf("EF_FPLMN: unexpected file size %d", size)
+	}
+	return size, nil
+}
+
+// simFileSize reads the file size of a GET RESPONSE reply: tag 80 of the
+// FCP template of USIMs, or bytes 3 and 4 of the reply of 2G SIMs.
+func simFileSize(fcp []byte) int {
+	if len(fcp) > 2 && fcp[0] == 0x62 {
+		for i := 2; i+1 < len(fcp); i += 2 + int(fcp[i+1]) {
+			if fcp[i] == 0x80 && fcp[i+1] == 2 && i+3 < len(fcp) {
+				return int(fcp[i+2])<<8 | int(fcp[i+3])
+			}
+		}
+		return 0
+	}
+	if len(fcp) >= 4 {
+		return int(fcp[2])<<8 | int(fcp[3])
+	}
+	return 0
+}
+
+// simCommand sends an AT+CRSM command, failing unless the SIM completed it.
+func (m *Manager) simCommand(imei string, cmd string) (atparse.SIMResponse, error) {
+	resp, err := m.command(context.Background(), imei, cmd)
+	if err != nil {
+		return atparse.SIMResponse{}, err
+	}
+	r, err := atparse.ParseCRSM(resp)
+	if err != nil {
+		return r, err
+	}
+	if !r.OK() {
+		return r, &SIMError{SW1: r.SW1, SW2: r.SW2}
+	}
+	return r, nil
+}
@@ -0,0 +1,27 @@
+package modem
+
+import "testing"
+
+func TestSimFileSize(t *testing.T) {
+	tests := []struct {
+		name string
+		fcp  []byte
+		want int
+	}{
+		{"USIM", []byte{0x62, 0x0f, 0x82, 0x02, 0x41, 0x21, 0x83, 0x02, 0x6f, 0x7b, 0x80, 0x02, 0x00, 0x0c, 0x88, 0x01, 0x68}, 12},
+		{"USIM size first", []byte{0x62, 0x04, 0x80, 0x02, 0x01, 0x2c}, 300},
+		{"USIM without size", []byte{0x62, 0x04, 0x82, 0x02, 0x41, 0x21}, 0},
+		{"USIM truncated size", []byte{0x62, 0x04, 0x80, 0x02, 0x00}, 0},
+		{"USIM size of other length", []byte{0x62, 0x03, 0x80, 0x01, 0x0c}, 0},
+		{"USIM length past the end", []byte{0x62, 0x10, 0x82, 0x7f, 0x80, 0x02, 0x00, 0x0c}, 0},
+		{"2G SIM", []byte{0x00, 0x00, 0x00, 0x0c, 0x6f, 0x7b, 0x04}, 12},
+		{"2G SIM short", []byte{0x00, 0x00, 0x00}, 0},
+		{"empty", nil, 0},
+		{"FCP tag only", []byte{0x62, 0x00}, 0},
+	}
+	for _, tt := range tests {
+		if got := simFileSize(tt.fcp); got != tt.want {
+			t.Errorf("%s: simFileSize(% x) = %d, want %d", tt.name, tt.fcp, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Package grpcserver exposes a modem.Manager over gRPC, so a central
+controller can manage the modems of many hosts.
+
+	m := modem.New()
+	m.AddFilter("1199", "68a3")
+	m.Monitor()
+
+	s := grpc.NewServer()
+	grpcserver.New(m).Register(s)
+	lis, _ := net.Listen("tcp", ":7600")
+	s.Serve(lis)
+
+The service definition is in modempb/modem.proto.
+*/
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/grpcserver/modempb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements modempb.ModemServiceServer on top of a Manager.
+type Server struct {
+	modempb.UnimplementedModemServiceServer
+	m *modem.Manager
+}
+
+// Create a service for m.
+func New(m *modem.Manager) *Server {
+	return &Server{m: m}
+}
+
+// Register the service on s.
+func (s *Server) Register(g *grpc.Server) {
+	modempb.RegisterModemServiceServer(g, s)
+}
+
+func (s *Server) List(ctx context.Context, req *modempb.ListRequest) (*modempb.ListResponse, error) {
+	resp := &modempb.ListResponse{}
+	for usb, d := range s.m.List() {
+		resp.Modems = append(resp.Modems, toProto(usb, d))
+	}
+	return resp, nil
+}
+
+func (s *Server) Events(req *modempb.EventsRequest, stream modempb.ModemService_EventsServer) error {
+	events := s.m.Events()
+	defer s.m.Unsubscribe(events)
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "Monitor stopped")
+			}
+			pe := &modempb.Event{
+				Type:  eventType(ev.Type),
+				Modem: toProto("", ev.Modem),
+				Seq:   ev.Seq,
+			}
+			if ev.Type == modem.EventSMS {
+				pe.Sms = &modempb.Sms{Sender: ev.SMS.Sender, Time: ev.SMS.Time, Text: ev.SMS.Text}
+			}
+			err := stream.Send(pe)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) SendAT(ctx context.Context, req *modempb.SendATRequest) (*modempb.SendATResponse, error) {
+	resp, err := s.m.SendATContext(ctx, req.Imei, req.Command)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &modempb.SendATResponse{Response: resp}, nil
+}
+
+func (s *Server) SendSMS(ctx context.Context, req *modempb.SendSMSRequest) (*modempb.SendSMSResponse, error) {
+	if err := s.m.SendSMSContext(ctx, req.Imei, req.Number, req.Text); err != nil {
+		return nil, toStatus(err)
+	}
+	return &modempb.SendSMSResponse{}, nil
+}
+
+func (s *Server) Connect(ctx context.Context, req *modempb.ConnectRequest) (*modempb.ConnectResponse, error) {
+	if err := s.m.ConnectContext(ctx, req.Imei, req.Apn); err != nil {
+		return nil, toStatus(err)
+	}
+	return &modempb.ConnectResponse{}, nil
+}
+
+func (s *Server) Disconnect(ctx context.Context, req *modempb.DisconnectRequest) (*modempb.DisconnectResponse, error) {
+	if err := s.m.Disconnect(req.Imei); err != nil {
+		return nil, toStatus(err)
+	}
+	return &modempb.DisconnectResponse{}, nil
+}
+
+func toProto(usb string, d modem.Modem) *modempb.Modem {
+	return &modempb.Modem{Usb: usb, Imei: d.Imei, Tty: d.Tty, Net: d.Net, Alias: d.Alias}
+}
+
+func eventType(t modem.EventType) modempb.Event_Type {
+	switch t {
+	case modem.EventUpdate:
+		return modempb.Event_UPDATE
+	case modem.EventRemove:
+		return modempb.Event_REMOVE
+	case modem.EventSMS:
+		return modempb.Event_SMS
+	case modem.EventConnect:
+		return modempb.Event_CONNECT
+	case modem.EventDisconnect:
+		return modempb.Event_DISCONNECT
+	case modem.EventReopen:
+		return modempb.Event_REOPEN
+	case modem.EventGaveUp:
+		return modempb.Event_GAVE_UP
+	case modem.EventJamming:
+		return modempb.Event_JAMMING
+	}
+	return modempb.Event_ADD
+}
+
+// toStatus maps manager errors to gRPC status codes.
+func toStatus(err error) error {
+	var cmdErr *modem.CommandError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, modem.ErrNoModem):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, modem.ErrTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.As(err, &cmdErr):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, modem.ErrUnsafeCommand), errors.Is(err, modem.ErrInvalidNumber),
+		errors.Is(err, modem.ErrInvalidAPN), errors.Is(err, modem.ErrInvalidText):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, modem.ErrCommandDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
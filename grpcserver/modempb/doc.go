@@ -0,0 +1,5 @@
+// Package modempb holds the protobuf and gRPC definitions of the modem
+// management service. The .pb.go files are generated from modem.proto.
+package modempb
+
+//go:generate buf generate --template buf.gen.yaml
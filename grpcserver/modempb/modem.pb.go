@@ -0,0 +1,916 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: modem.proto
+
+package modempb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Event_Type int32
+
+const (
+	Event_ADD        Event_Type = 0
+	Event_UPDATE     Event_Type = 1
+	Event_REMOVE     Event_Type = 2
+	Event_SMS        Event_Type = 3
+	Event_CONNECT    Event_Type = 4
+	Event_DISCONNECT Event_Type = 5
+	Event_REOPEN     Event_Type = 6
+	Event_GAVE_UP    Event_Type = 7
+	Event_JAMMING    Event_Type = 8
+)
+
+// Enum value maps for Event_Type.
+var (
+	Event_Type_name = map[int32]string{
+		0: "ADD",
+		1: "UPDATE",
+		2: "REMOVE",
+		3: "SMS",
+		4: "CONNECT",
+		5: "DISCONNECT",
+		6: "REOPEN",
+		7: "GAVE_UP",
+		8: "JAMMING",
+	}
+	Event_Type_value = map[string]int32{
+		"ADD":        0,
+		"UPDATE":     1,
+		"REMOVE":     2,
+		"SMS":        3,
+		"CONNECT":    4,
+		"DISCONNECT": 5,
+		"REOPEN":     6,
+		"GAVE_UP":    7,
+		"JAMMING":    8,
+	}
+)
+
+func (x Event_Type) Enum() *Event_Type {
+	p := new(Event_Type)
+	*p = x
+	return p
+}
+
+func (x Event_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Event_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_modem_proto_enumTypes[0].Descriptor()
+}
+
+func (Event_Type) Type() protoreflect.EnumType {
+	return &file_modem_proto_enumTypes[0]
+}
+
+func (x Event_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Event_Type.Descriptor instead.
+func (Event_Type) EnumDescriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{4, 0}
+}
+
+type Modem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usb           string                 `protobuf:"bytes,1,opt,name=usb,proto3" json:"usb,omitempty"`
+	Imei          string                 `protobuf:"bytes,2,opt,name=imei,proto3" json:"imei,omitempty"`
+	Tty           string                 `protobuf:"bytes,3,opt,name=tty,proto3" json:"tty,omitempty"`
+	Net           string                 `protobuf:"bytes,4,opt,name=net,proto3" json:"net,omitempty"`
+	Alias         string                 `protobuf:"bytes,5,opt,name=alias,proto3" json:"alias,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Modem) Reset() {
+	*x = Modem{}
+	mi := &file_modem_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Modem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Modem) ProtoMessage() {}
+
+func (x *Modem) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Modem.ProtoReflect.Descriptor instead.
+func (*Modem) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Modem) GetUsb() string {
+	if x != nil {
+		return x.Usb
+	}
+	return ""
+}
+
+func (x *Modem) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *Modem) GetTty() string {
+	if x != nil {
+		return x.Tty
+	}
+	return ""
+}
+
+func (x *Modem) GetNet() string {
+	if x != nil {
+		return x.Net
+	}
+	return ""
+}
+
+func (x *Modem) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_modem_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{1}
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Modems        []*Modem               `protobuf:"bytes,1,rep,name=modems,proto3" json:"modems,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_modem_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListResponse) GetModems() []*Modem {
+	if x != nil {
+		return x.Modems
+	}
+	return nil
+}
+
+type EventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventsRequest) Reset() {
+	*x = EventsRequest{}
+	mi := &file_modem_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventsRequest) ProtoMessage() {}
+
+func (x *EventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventsRequest.ProtoReflect.Descriptor instead.
+func (*EventsRequest) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{3}
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  Event_Type             `protobuf:"varint,1,opt,name=type,proto3,enum=modem.v1.Event_Type" json:"type,omitempty"`
+	Modem *Modem                 `protobuf:"bytes,2,opt,name=modem,proto3" json:"modem,omitempty"`
+	// Set for SMS events.
+	Sms *Sms `protobuf:"bytes,3,opt,name=sms,proto3" json:"sms,omitempty"`
+	// Increases by one with every event of the manager; a gap means events
+	// were dropped because the stream fell behind.
+	Seq           uint64 `protobuf:"varint,4,opt,name=seq,proto3" json:"seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_modem_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Event) GetType() Event_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Event_ADD
+}
+
+func (x *Event) GetModem() *Modem {
+	if x != nil {
+		return x.Modem
+	}
+	return nil
+}
+
+func (x *Event) GetSms() *Sms {
+	if x != nil {
+		return x.Sms
+	}
+	return nil
+}
+
+func (x *Event) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+type Sms struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        string                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Time          string                 `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sms) Reset() {
+	*x = Sms{}
+	mi := &file_modem_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sms) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sms) ProtoMessage() {}
+
+func (x *Sms) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sms.ProtoReflect.Descriptor instead.
+func (*Sms) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Sms) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *Sms) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *Sms) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type SendATRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imei          string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendATRequest) Reset() {
+	*x = SendATRequest{}
+	mi := &file_modem_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendATRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendATRequest) ProtoMessage() {}
+
+func (x *SendATRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendATRequest.ProtoReflect.Descriptor instead.
+func (*SendATRequest) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SendATRequest) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *SendATRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+type SendATResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Response      string                 `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendATResponse) Reset() {
+	*x = SendATResponse{}
+	mi := &file_modem_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendATResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendATResponse) ProtoMessage() {}
+
+func (x *SendATResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendATResponse.ProtoReflect.Descriptor instead.
+func (*SendATResponse) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SendATResponse) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+type SendSMSRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imei          string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"`
+	Number        string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendSMSRequest) Reset() {
+	*x = SendSMSRequest{}
+	mi := &file_modem_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendSMSRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendSMSRequest) ProtoMessage() {}
+
+func (x *SendSMSRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendSMSRequest.ProtoReflect.Descriptor instead.
+func (*SendSMSRequest) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SendSMSRequest) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *SendSMSRequest) GetNumber() string {
+	if x != nil {
+		return x.Number
+	}
+	return ""
+}
+
+func (x *SendSMSRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type SendSMSResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendSMSResponse) Reset() {
+	*x = SendSMSResponse{}
+	mi := &file_modem_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendSMSResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendSMSResponse) ProtoMessage() {}
+
+func (x *SendSMSResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendSMSResponse.ProtoReflect.Descriptor instead.
+func (*SendSMSResponse) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{9}
+}
+
+type ConnectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imei          string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"`
+	Apn           string                 `protobuf:"bytes,2,opt,name=apn,proto3" json:"apn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectRequest) Reset() {
+	*x = ConnectRequest{}
+	mi := &file_modem_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectRequest) ProtoMessage() {}
+
+func (x *ConnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectRequest.ProtoReflect.Descriptor instead.
+func (*ConnectRequest) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConnectRequest) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *ConnectRequest) GetApn() string {
+	if x != nil {
+		return x.Apn
+	}
+	return ""
+}
+
+type ConnectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectResponse) Reset() {
+	*x = ConnectResponse{}
+	mi := &file_modem_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectResponse) ProtoMessage() {}
+
+func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectResponse.ProtoReflect.Descriptor instead.
+func (*ConnectResponse) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{11}
+}
+
+type DisconnectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imei          string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisconnectRequest) Reset() {
+	*x = DisconnectRequest{}
+	mi := &file_modem_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectRequest) ProtoMessage() {}
+
+func (x *DisconnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectRequest.ProtoReflect.Descriptor instead.
+func (*DisconnectRequest) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DisconnectRequest) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+type DisconnectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisconnectResponse) Reset() {
+	*x = DisconnectResponse{}
+	mi := &file_modem_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisconnectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisconnectResponse) ProtoMessage() {}
+
+func (x *DisconnectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modem_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisconnectResponse.ProtoReflect.Descriptor instead.
+func (*DisconnectResponse) Descriptor() ([]byte, []int) {
+	return file_modem_proto_rawDescGZIP(), []int{13}
+}
+
+var File_modem_proto protoreflect.FileDescriptor
+
+const file_modem_proto_rawDesc = "" +
+	"\n" +
+	"\vmodem.proto\x12\bmodem.v1\"g\n" +
+	"\x05Modem\x12\x10\n" +
+	"\x03usb\x18\x01 \x01(\tR\x03usb\x12\x12\n" +
+	"\x04imei\x18\x02 \x01(\tR\x04imei\x12\x10\n" +
+	"\x03tty\x18\x03 \x01(\tR\x03tty\x12\x10\n" +
+	"\x03net\x18\x04 \x01(\tR\x03net\x12\x14\n" +
+	"\x05alias\x18\x05 \x01(\tR\x05alias\"\r\n" +
+	"\vListRequest\"7\n" +
+	"\fListResponse\x12'\n" +
+	"\x06modems\x18\x01 \x03(\v2\x0f.modem.v1.ModemR\x06modems\"\x0f\n" +
+	"\rEventsRequest\"\x80\x02\n" +
+	"\x05Event\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.modem.v1.Event.TypeR\x04type\x12%\n" +
+	"\x05modem\x18\x02 \x01(\v2\x0f.modem.v1.ModemR\x05modem\x12\x1f\n" +
+	"\x03sms\x18\x03 \x01(\v2\r.modem.v1.SmsR\x03sms\x12\x10\n" +
+	"\x03seq\x18\x04 \x01(\x04R\x03seq\"s\n" +
+	"\x04Type\x12\a\n" +
+	"\x03ADD\x10\x00\x12\n" +
+	"\n" +
+	"\x06UPDATE\x10\x01\x12\n" +
+	"\n" +
+	"\x06REMOVE\x10\x02\x12\a\n" +
+	"\x03SMS\x10\x03\x12\v\n" +
+	"\aCONNECT\x10\x04\x12\x0e\n" +
+	"\n" +
+	"DISCONNECT\x10\x05\x12\n" +
+	"\n" +
+	"\x06REOPEN\x10\x06\x12\v\n" +
+	"\aGAVE_UP\x10\a\x12\v\n" +
+	"\aJAMMING\x10\b\"E\n" +
+	"\x03Sms\x12\x16\n" +
+	"\x06sender\x18\x01 \x01(\tR\x06sender\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\tR\x04time\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"=\n" +
+	"\rSendATRequest\x12\x12\n" +
+	"\x04imei\x18\x01 \x01(\tR\x04imei\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\",\n" +
+	"\x0eSendATResponse\x12\x1a\n" +
+	"\bresponse\x18\x01 \x01(\tR\bresponse\"P\n" +
+	"\x0eSendSMSRequest\x12\x12\n" +
+	"\x04imei\x18\x01 \x01(\tR\x04imei\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\tR\x06number\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"\x11\n" +
+	"\x0fSendSMSResponse\"6\n" +
+	"\x0eConnectRequest\x12\x12\n" +
+	"\x04imei\x18\x01 \x01(\tR\x04imei\x12\x10\n" +
+	"\x03apn\x18\x02 \x01(\tR\x03apn\"\x11\n" +
+	"\x0fConnectResponse\"'\n" +
+	"\x11DisconnectRequest\x12\x12\n" +
+	"\x04imei\x18\x01 \x01(\tR\x04imei\"\x14\n" +
+	"\x12DisconnectResponse2\x81\x03\n" +
+	"\fModemService\x125\n" +
+	"\x04List\x12\x15.modem.v1.ListRequest\x1a\x16.modem.v1.ListResponse\x124\n" +
+	"\x06Events\x12\x17.modem.v1.EventsRequest\x1a\x0f.modem.v1.Event0\x01\x12;\n" +
+	"\x06SendAT\x12\x17.modem.v1.SendATRequest\x1a\x18.modem.v1.SendATResponse\x12>\n" +
+	"\aSendSMS\x12\x18.modem.v1.SendSMSRequest\x1a\x19.modem.v1.SendSMSResponse\x12>\n" +
+	"\aConnect\x12\x18.modem.v1.ConnectRequest\x1a\x19.modem.v1.ConnectResponse\x12G\n" +
+	"\n" +
+	"Disconnect\x12\x1b.modem.v1.DisconnectRequest\x1a\x1c.modem.v1.DisconnectResponseB.Z,github.com/ausrasul/modem/grpcserver/modempbb\x06proto3"
+
+var (
+	file_modem_proto_rawDescOnce sync.Once
+	file_modem_proto_rawDescData []byte
+)
+
+func file_modem_proto_rawDescGZIP() []byte {
+	file_modem_proto_rawDescOnce.Do(func() {
+		file_modem_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_modem_proto_rawDesc), len(file_modem_proto_rawDesc)))
+	})
+	return file_modem_proto_rawDescData
+}
+
+var file_modem_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_modem_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_modem_proto_goTypes = []any{
+	(Event_Type)(0),            // 0: modem.v1.Event.Type
+	(*Modem)(nil),              // 1: modem.v1.Modem
+	(*ListRequest)(nil),        // 2: modem.v1.ListRequest
+	(*ListResponse)(nil),       // 3: modem.v1.ListResponse
+	(*EventsRequest)(nil),      // 4: modem.v1.EventsRequest
+	(*Event)(nil),              // 5: modem.v1.Event
+	(*Sms)(nil),                // 6: modem.v1.Sms
+	(*SendATRequest)(nil),      // 7: modem.v1.SendATRequest
+	(*SendATResponse)(nil),     // 8: modem.v1.SendATResponse
+	(*SendSMSRequest)(nil),     // 9: modem.v1.SendSMSRequest
+	(*SendSMSResponse)(nil),    // 10: modem.v1.SendSMSResponse
+	(*ConnectRequest)(nil),     // 11: modem.v1.ConnectRequest
+	(*ConnectResponse)(nil),    // 12: modem.v1.ConnectResponse
+	(*DisconnectRequest)(nil),  // 13: modem.v1.DisconnectRequest
+	(*DisconnectResponse)(nil), // 14: modem.v1.DisconnectResponse
+}
+var file_modem_proto_depIdxs = []int32{
+	1,  // 0: modem.v1.ListResponse.modems:type_name -> modem.v1.Modem
+	0,  // 1: modem.v1.Event.type:type_name -> modem.v1.Event.Type
+	1,  // 2: modem.v1.Event.modem:type_name -> modem.v1.Modem
+	6,  // 3: modem.v1.Event.sms:type_name -> modem.v1.Sms
+	2,  // 4: modem.v1.ModemService.List:input_type -> modem.v1.ListRequest
+	4,  // 5: modem.v1.ModemService.Events:input_type -> modem.v1.EventsRequest
+	7,  // 6: modem.v1.ModemService.SendAT:input_type -> modem.v1.SendATRequest
+	9,  // 7: modem.v1.ModemService.SendSMS:input_type -> modem.v1.SendSMSRequest
+	11, // 8: modem.v1.ModemService.Connect:input_type -> modem.v1.ConnectRequest
+	13, // 9: modem.v1.ModemService.Disconnect:input_type -> modem.v1.DisconnectRequest
+	3,  // 10: modem.v1.ModemService.List:output_type -> modem.v1.ListResponse
+	5,  // 11: modem.v1.ModemService.Events:output_type -> modem.v1.Event
+	8,  // 12: modem.v1.ModemService.SendAT:output_type -> modem.v1.SendATResponse
+	10, // 13: modem.v1.ModemService.SendSMS:output_type -> modem.v1.SendSMSResponse
+	12, // 14: modem.v1.ModemService.Connect:output_type -> modem.v1.ConnectResponse
+	14, // 15: modem.v1.ModemService.Disconnect:output_type -> modem.v1.DisconnectResponse
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_modem_proto_init() }
+func file_modem_proto_init() {
+	if File_modem_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_modem_proto_rawDesc), len(file_modem_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_modem_proto_goTypes,
+		DependencyIndexes: file_modem_proto_depIdxs,
+		EnumInfos:         file_modem_proto_enumTypes,
+		MessageInfos:      file_modem_proto_msgTypes,
+	}.Build()
+	File_modem_proto = out.File
+	file_modem_proto_goTypes = nil
+	file_modem_proto_depIdxs = nil
+}
@@ -0,0 +1,325 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: modem.proto
+
+package modempb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ModemService_List_FullMethodName       = "/modem.v1.ModemService/List"
+	ModemService_Events_FullMethodName     = "/modem.v1.ModemService/Events"
+	ModemService_SendAT_FullMethodName     = "/modem.v1.ModemService/SendAT"
+	ModemService_SendSMS_FullMethodName    = "/modem.v1.ModemService/SendSMS"
+	ModemService_Connect_FullMethodName    = "/modem.v1.ModemService/Connect"
+	ModemService_Disconnect_FullMethodName = "/modem.v1.ModemService/Disconnect"
+)
+
+// ModemServiceClient is the client API for ModemService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Remote management of the modems attached to one host.
+type ModemServiceClient interface {
+	// Ready modems, keyed by USB device node.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Modem lifecycle events, until the client cancels or the monitor stops.
+	Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	SendAT(ctx context.Context, in *SendATRequest, opts ...grpc.CallOption) (*SendATResponse, error)
+	SendSMS(ctx context.Context, in *SendSMSRequest, opts ...grpc.CallOption) (*SendSMSResponse, error)
+	// Activate a packet data connection.
+	Connect(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*ConnectResponse, error)
+	Disconnect(ctx context.Context, in *DisconnectRequest, opts ...grpc.CallOption) (*DisconnectResponse, error)
+}
+
+type modemServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewModemServiceClient(cc grpc.ClientConnInterface) ModemServiceClient {
+	return &modemServiceClient{cc}
+}
+
+func (c *modemServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, ModemService_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *modemServiceClient) Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ModemService_ServiceDesc.Streams[0], ModemService_Events_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ModemService_EventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *modemServiceClient) SendAT(ctx context.Context, in *SendATRequest, opts ...grpc.CallOption) (*SendATResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendATResponse)
+	err := c.cc.Invoke(ctx, ModemService_SendAT_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *modemServiceClient) SendSMS(ctx context.Context, in *SendSMSRequest, opts ...grpc.CallOption) (*SendSMSResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendSMSResponse)
+	err := c.cc.Invoke(ctx, ModemService_SendSMS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *modemServiceClient) Connect(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*ConnectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConnectResponse)
+	err := c.cc.Invoke(ctx, ModemService_Connect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *modemServiceClient) Disconnect(ctx context.Context, in *DisconnectRequest, opts ...grpc.CallOption) (*DisconnectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisconnectResponse)
+	err := c.cc.Invoke(ctx, ModemService_Disconnect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ModemServiceServer is the server API for ModemService service.
+// All implementations must embed UnimplementedModemServiceServer
+// for forward compatibility.
+//
+// Remote management of the modems attached to one host.
+type ModemServiceServer interface {
+	// Ready modems, keyed by USB device node.
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Modem lifecycle events, until the client cancels or the monitor stops.
+	Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error
+	SendAT(context.Context, *SendATRequest) (*SendATResponse, error)
+	SendSMS(context.Context, *SendSMSRequest) (*SendSMSResponse, error)
+	// Activate a packet data connection.
+	Connect(context.Context, *ConnectRequest) (*ConnectResponse, error)
+	Disconnect(context.Context, *DisconnectRequest) (*DisconnectResponse, error)
+	mustEmbedUnimplementedModemServiceServer()
+}
+
+// UnimplementedModemServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedModemServiceServer struct{}
+
+func (UnimplementedModemServiceServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedModemServiceServer) Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method Events not implemented")
+}
+func (UnimplementedModemServiceServer) SendAT(context.Context, *SendATRequest) (*SendATResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendAT not implemented")
+}
+func (UnimplementedModemServiceServer) SendSMS(context.Context, *SendSMSRequest) (*SendSMSResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendSMS not implemented")
+}
+func (UnimplementedModemServiceServer) Connect(context.Context, *ConnectRequest) (*ConnectResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Connect not implemented")
+}
+func (UnimplementedModemServiceServer) Disconnect(context.Context, *DisconnectRequest) (*DisconnectResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Disconnect not implemented")
+}
+func (UnimplementedModemServiceServer) mustEmbedUnimplementedModemServiceServer() {}
+func (UnimplementedModemServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeModemServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ModemServiceServer will
+// result in compilation errors.
+type UnsafeModemServiceServer interface {
+	mustEmbedUnimplementedModemServiceServer()
+}
+
+func RegisterModemServiceServer(s grpc.ServiceRegistrar, srv ModemServiceServer) {
+	// If the following call panics, it indicates UnimplementedModemServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ModemService_ServiceDesc, srv)
+}
+
+func _ModemService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModemServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ModemService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModemServiceServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ModemService_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ModemServiceServer).Events(m, &grpc.GenericServerStream[EventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ModemService_EventsServer = grpc.ServerStreamingServer[Event]
+
+func _ModemService_SendAT_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendATRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModemServiceServer).SendAT(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ModemService_SendAT_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModemServiceServer).SendAT(ctx, req.(*SendATRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ModemService_SendSMS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendSMSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModemServiceServer).SendSMS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ModemService_SendSMS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModemServiceServer).SendSMS(ctx, req.(*SendSMSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ModemService_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModemServiceServer).Connect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ModemService_Connect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModemServiceServer).Connect(ctx, req.(*ConnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ModemService_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModemServiceServer).Disconnect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ModemService_Disconnect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModemServiceServer).Disconnect(ctx, req.(*DisconnectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ModemService_ServiceDesc is the grpc.ServiceDesc for ModemService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ModemService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "modem.v1.ModemService",
+	HandlerType: (*ModemServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _ModemService_List_Handler,
+		},
+		{
+			MethodName: "SendAT",
+			Handler:    _ModemService_SendAT_Handler,
+		},
+		{
+			MethodName: "SendSMS",
+			Handler:    _ModemService_SendSMS_Handler,
+		},
+		{
+			MethodName: "Connect",
+			Handler:    _ModemService_Connect_Handler,
+		},
+		{
+			MethodName: "Disconnect",
+			Handler:    _ModemService_Disconnect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Events",
+			Handler:       _ModemService_Events_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "modem.proto",
+}
@@ -0,0 +1,80 @@
+package modem
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks lets integrators instrument the manager with their own metrics or
+// tracing. Nil fields are skipped. Hooks run synchronously on the
+// goroutine doing the work and should return quickly.
+type Hooks struct {
+	// Called before the identity probe of a candidate AT port.
+	OnProbeStart func(port string)
+	// Called when the probe finished, with the IMEI found or the error.
+	OnProbeEnd func(port string, imei string, err error, elapsed time.Duration)
+	// Called after every AT command sent by the manager. Arguments that
+	// carry a PIN or password are redacted, see CommandInfo.
+	OnCommand func(port string, cmd string, err error, elapsed time.Duration)
+	// Called before every AT command with the context of the call that
+	// caused it. The returned function, if any, is called with the
+	// command's outcome. Meant for tracers that wrap commands in spans.
+	OnCommandStart func(ctx context.Context, c CommandInfo) func(err error)
+	// Called for every modem event in Seq order, once it is queued for
+	// Events subscribers.
+	OnEvent func(ev ModemEvent)
+	// Called after every run of a scheduled task, with the reply of its
+	// AT command, see Task.
+	OnTask func(name string, d Modem, reply string, err error)
+}
+
+// AT command about to be sent, as passed to OnCommandStart.
+type CommandInfo struct {
+	Port string
+	Imei string // empty while the modem is being probed
+	Cmd  string // with the arguments of AT+CPIN, AT+CLCK and AT+CPWD as ***
+}
+
+// Install instrumentation hooks. Must be called before Monitor.
+func (m *Manager) SetHooks(h Hooks) {
+	m.hooks = h
+}
+
+func (h Hooks) probeStart(port string) {
+	if h.OnProbeStart != nil {
+		h.OnProbeStart(port)
+	}
+}
+
+func (h Hooks) probeEnd(port, imei string, err error, elapsed time.Duration) {
+	if h.OnProbeEnd != nil {
+		h.OnProbeEnd(port, imei, err, elapsed)
+	}
+}
+
+func (h Hooks) command(port, cmd string, err error, elapsed time.Duration) {
+	if h.OnCommand != nil {
+		h.OnCommand(port, cmd, err, elapsed)
+	}
+}
+
+func (h Hooks) commandStart(ctx context.Context, c CommandInfo) func(error) {
+	if h.OnCommandStart != nil {
+		if done := h.OnCommandStart(ctx, c); done != nil {
+			return done
+		}
+	}
+	return func(error) {}
+}
+
+func (h Hooks) event(ev ModemEvent) {
+	if h.OnEvent != nil {
+		h.OnEvent(ev)
+	}
+}
+
+func (h Hooks) task(name string, d Modem, reply string, err error) {
+	if h.OnTask != nil {
+		h.OnTask(name, d, reply, err)
+	}
+}
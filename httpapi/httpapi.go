@@ -0,0 +1,196 @@
+/*
+Package httpapi serves a modem.Manager as a small JSON API:
+
+	GET  /modems               ready modems
+	GET  /modems/{imei}/signal signal quality
+	POST /modems/{imei}/sms    send {"number": "...", "text": "..."}
+	GET  /events               modem events as server-sent events, with the Seq as id
+
+Mount New(m) on any mux, or pass Plugin(addr) to modem.WithPlugins to run
+a server alongside the monitor.
+*/
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ausrasul/modem"
+)
+
+// Modem as rendered in responses and events.
+type Modem struct {
+	USB   string `json:"usb,omitempty"`
+	Imei  string `json:"imei"`
+	Alias string `json:"alias,omitempty"`
+	Tty   string `json:"tty"`
+	Net   string `json:"net"`
+}
+
+// Signal as rendered by the signal endpoint.
+type Signal struct {
+	RSSI int `json:"rssi"`
+	BER  int `json:"ber"`
+	DBm  int `json:"dbm,omitempty"`
+}
+
+// Body of the SMS endpoint.
+type SMS struct {
+	Number string `json:"number"`
+	Text   string `json:"text"`
+}
+
+// Received message, included in sms events.
+type Received struct {
+	Sender string `json:"sender"`
+	Time   string `json:"time"`
+	Text   string `json:"text"`
+}
+
+// Data of a server-sent event.
+type Event struct {
+	Modem
+	SMS *Received `json:"sms,omitempty"`
+}
+
+type handler struct {
+	m *modem.Manager
+}
+
+// Create an http.Handler serving the API for m.
+func New(m *modem.Manager) http.Handler {
+	h := handler{m: m}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /modems", h.list)
+	mux.HandleFunc("GET /modems/{imei}/signal", h.signal)
+	mux.HandleFunc("POST /modems/{imei}/sms", h.sms)
+	mux.HandleFunc("GET /events", h.events)
+	return mux
+}
+
+func (h handler) list(w http.ResponseWriter, r *http.Request) {
+	list := []Modem{}
+	for usb, d := range h.m.List() {
+		list = append(list, view(usb, d))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h handler) signal(w http.ResponseWriter, r *http.Request) {
+	s, err := h.m.SignalContext(r.Context(), r.PathValue("imei"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Signal{RSSI: s.RSSI, BER: s.BER, DBm: s.DBm()})
+}
+
+func (h handler) sms(w http.ResponseWriter, r *http.Request) {
+	var body SMS
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Number == "" {
+		http.Error(w, "Expected {\"number\": ..., \"text\": ...}", http.StatusBadRequest)
+		return
+	}
+	if err := h.m.SendSMSContext(r.Context(), r.PathValue("imei"), body.Number, body.Text); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	events := h.m.Events()
+	defer h.m.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			e := Event{Modem: view("", ev.Modem)}
+			if ev.Type == modem.EventSMS {
+				e.SMS = &Received{Sender: ev.SMS.Sender, Time: ev.SMS.Time, Text: ev.SMS.Text}
+			}
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+func view(usb string, d modem.Modem) Modem {
+	return Modem{USB: usb, Imei: d.Imei, Alias: d.Alias, Tty: d.Tty, Net: d.Net}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps manager errors to HTTP status codes.
+func writeError(w http.ResponseWriter, err error) {
+	var cmdErr *modem.CommandError
+	code := http.StatusServiceUnavailable
+	switch {
+	case errors.Is(err, modem.ErrNoModem):
+		code = http.StatusNotFound
+	case errors.Is(err, modem.ErrTimeout):
+		code = http.StatusGatewayTimeout
+	case errors.As(err, &cmdErr):
+		code = http.StatusBadGateway
+	case errors.Is(err, modem.ErrUnsafeCommand), errors.Is(err, modem.ErrInvalidNumber),
+		errors.Is(err, modem.ErrInvalidText):
+		code = http.StatusBadRequest
+	case errors.Is(err, modem.ErrCommandDenied):
+		code = http.StatusForbidden
+	}
+	http.Error(w, err.Error(), code)
+}
+
+type plugin struct {
+	addr string
+	srv  *http.Server
+}
+
+// Plugin serves the API on addr while the manager is monitoring.
+func Plugin(addr string) modem.Plugin {
+	return &plugin{addr: addr}
+}
+
+func (p *plugin) Start(m *modem.Manager) error {
+	lis, err := net.Listen("tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	p.srv = &http.Server{Handler: New(m), ReadHeaderTimeout: 10 * time.Second}
+	go p.srv.Serve(lis)
+	return nil
+}
+
+func (p *plugin) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Event streams only end with the monitor, don't wait for them forever.
+	if err := p.srv.Shutdown(ctx); err != nil {
+		return p.srv.Close()
+	}
+	return nil
+}
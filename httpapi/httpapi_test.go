@@ -0,0 +1,34 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/httpapi"
+)
+
+func TestSMSErrors(t *testing.T) {
+	m := modem.New(modem.WithConfig(&modem.Config{Commands: modem.CommandPolicy{Deny: []string{"AT+CMGS=\"+4670"}}}))
+	h := httpapi.New(m)
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"no modem", `{"number": "+46123456", "text": "hi"}`, http.StatusNotFound},
+		{"denied", `{"number": "+4670123456", "text": "hi"}`, http.StatusForbidden},
+		{"invalid number", `{"number": "12\"34", "text": "hi"}`, http.StatusBadRequest},
+		{"no number", `{"text": "hi"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/modems/490154203237518/sms", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package modem
+
+import "strings"
+
+// JammingState is what the jamming detection of a modem last reported.
+type JammingState int
+
+const (
+	JammingUnknown  JammingState = iota // nothing reported, or no detection
+	JammingNone                         // the modem reported normal operation
+	JammingDetected                     // the modem reported jamming
+)
+
+func (s JammingState) String() string {
+	switch s {
+	case JammingNone:
+		return "none"
+	case JammingDetected:
+		return "jammed"
+	}
+	return "unknown"
+}
+
+// Commands enabling the jamming indications of modems, by USB vendor id.
+var jammingCommands = map[string]string{
+	"2c7c": "AT+QJDR=1", // Quectel, +QJDR: <status>
+	"1bc7": "AT#JDR=2",  // Telit, #JDR: JAMMED|OPERATIVE
+}
+
+// parseJamming reports whether line is a jamming indication, and the state
+// it gives.
+func parseJamming(line string) (JammingState, bool) {
+	rest, ok := strings.CutPrefix(line, "+QJDR:")
+	if !ok {
+		rest, ok = strings.CutPrefix(line, "#JDR:")
+	}
+	if !ok {
+		return JammingUnknown, false
+	}
+	switch strings.ToUpper(strings.TrimSpace(rest)) {
+	case "1", "JAMMED":
+		return JammingDetected, true
+	case "0", "OPERATIVE", "NOJAMMING":
+		return JammingNone, true
+	}
+	return JammingUnknown, true
+}
+
+// enableJamming turns on the jamming indications of the modem on an open
+// port, if its vendor has them.
+func (m *Manager) enableJamming(p *atPort) {
+	m.mu.Lock()
+	var vid string
+	for _, d := range m.devices {
+		if d.Tty == p.node {
+			vid = d.Vid
+		}
+	}
+	m.mu.Unlock()
+	cmd, ok := jammingCommands[vid]
+	if !ok {
+		return
+	}
+	if _, err := p.Command(cmd, commandTimeout); err != nil {
+		m.log.Debug("jamming detection not enabled", "tty", p.node, "err", err)
+	}
+}
+
+// jamming records a jamming indication of the modem with the given IMEI
+// and emits EventJamming if its state changed.
+func (m *Manager) jamming(imei string, s JammingState) {
+	m.mu.Lock()
+	var changed *Modem
+	for k, d := range m.devices {
+		if d.State == StateReady && d.Imei == imei && d.Jamming != s {
+			d.Jamming = s
+			m.devices[k] = d
+			changed = &d
+		}
+	}
+	m.mu.Unlock()
+	if changed == nil {
+		return
+	}
+	if s == JammingDetected {
+		m.log.Warn("jamming detected", "imei", imei)
+	} else {
+		m.log.Info("jamming state changed", "imei", imei, "state", s)
+	}
+	m.emit(ModemEvent{Type: EventJamming, Modem: *changed})
+}
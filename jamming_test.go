@@ -0,0 +1,30 @@
+package modem
+
+import "testing"
+
+func TestParseJamming(t *testing.T) {
+	tests := []struct {
+		line  string
+		want  JammingState
+		isInd bool
+	}{
+		{"+QJDR: 1", JammingDetected, true},
+		{"+QJDR: 0", JammingNone, true},
+		{"+QJDR:1", JammingDetected, true},
+		{"+QJDR: 2", JammingUnknown, true},
+		{"#JDR: JAMMED", JammingDetected, true},
+		{"#JDR: OPERATIVE", JammingNone, true},
+		{"#JDR: NOJAMMING", JammingNone, true},
+		{"#JDR: jammed", JammingDetected, true},
+		{"#JDR: ", JammingUnknown, true},
+		{"+CSQ: 20,99", JammingUnknown, false},
+		{"OK", JammingUnknown, false},
+		{"", JammingUnknown, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseJamming(tt.line)
+		if got != tt.want || ok != tt.isInd {
+			t.Errorf("parseJamming(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.isInd)
+		}
+	}
+}
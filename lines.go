@@ -0,0 +1,77 @@
+package modem
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// lineReader splits what a modem port sends into lines. "\r\n", "\r" and
+// "\n" all end a line, so modems disagreeing on line endings, or on
+// V.250's S3/S4 settings, read the same. Lines are trimmed and an echo of
+// the last command is dropped.
+type lineReader struct {
+	r     io.Reader
+	buf   []byte
+	chunk []byte
+	cr    bool   // the last line ended with \r, a following \n belongs to it
+	echo  string // line to drop, the command just sent
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: r, chunk: make([]byte, 256)}
+}
+
+// Expect drops the echo of cmd from the lines that follow.
+func (l *lineReader) Expect(cmd string) {
+	l.echo = strings.TrimSpace(cmd)
+}
+
+// Next returns the next complete line already read, if there is one.
+// Blank lines are returned too.
+func (l *lineReader) Next() (string, bool) {
+	for {
+		if l.cr && len(l.buf) > 0 {
+			l.cr = false
+			if l.buf[0] == '\n' {
+				l.buf = l.buf[1:]
+			}
+		}
+		i := bytes.IndexAny(l.buf, "\r\n")
+		if i < 0 {
+			return "", false
+		}
+		line := strings.TrimSpace(string(l.buf[:i]))
+		l.cr = l.buf[i] == '\r'
+		l.buf = l.buf[i+1:]
+		if line != "" && line == l.echo {
+			l.echo = ""
+			continue
+		}
+		return line, true
+	}
+}
+
+// Prompt reports whether the incomplete line is the "> " prompt for more
+// input, and drops it if so.
+func (l *lineReader) Prompt() bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(l.buf), []byte(">")) {
+		return false
+	}
+	l.buf = l.buf[:0]
+	return true
+}
+
+// Fill reads once from the port. What was read is kept even on error.
+func (l *lineReader) Fill() error {
+	n, err := l.r.Read(l.chunk)
+	l.buf = append(l.buf, l.chunk[:n]...)
+	return err
+}
+
+// Reset drops everything buffered.
+func (l *lineReader) Reset() {
+	l.buf = l.buf[:0]
+	l.cr = false
+	l.echo = ""
+}
@@ -0,0 +1,93 @@
+package modem
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+// chunks returns one chunk per Read, then io.EOF.
+type chunks []string
+
+func (c *chunks) Read(b []byte) (int, error) {
+	if len(*c) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, (*c)[0])
+	*c = (*c)[1:]
+	return n, nil
+}
+
+func TestLineReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks chunks
+		echo   string
+		want   []string
+	}{
+		{"crlf", chunks{"\r\nOK\r\n"}, "", []string{"", "OK"}},
+		{"lf", chunks{"\n+CSQ: 17,99\n\nOK\n"}, "", []string{"", "+CSQ: 17,99", "", "OK"}},
+		{"cr", chunks{"\r+CSQ: 17,99\r\rOK\r"}, "", []string{"", "+CSQ: 17,99", "", "OK"}},
+		{"crlf split across reads", chunks{"OK\r", "\nERROR\r\n"}, "", []string{"OK", "ERROR"}},
+		{"trimmed", chunks{"  OK \r\n"}, "", []string{"OK"}},
+		{"echo dropped", chunks{"AT+CSQ\r\r\n+CSQ: 17,99\r\n\r\nOK\r\n"}, "AT+CSQ", []string{"", "+CSQ: 17,99", "", "OK"}},
+		{"echo dropped once", chunks{"AT\r\r\nAT\r\nOK\r\n"}, "AT", []string{"", "AT", "OK"}},
+		{"incomplete line kept", chunks{"OK\r\n+CMTI: \"SM\""}, "", []string{"OK"}},
+	}
+	for _, tt := range tests {
+		l := newLineReader(&tt.chunks)
+		l.Expect(tt.echo)
+		var got []string
+		for {
+			for line, ok := l.Next(); ok; line, ok = l.Next() {
+				got = append(got, line)
+			}
+			if err := l.Fill(); err != nil {
+				break
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: lines %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLineReaderPrompt(t *testing.T) {
+	tests := []struct {
+		in     string
+		prompt bool
+	}{
+		{"\r\n> ", true},
+		{"\r\n>", true},
+		{"\r\nOK", false},
+		{"\r\n", false},
+	}
+	for _, tt := range tests {
+		l := newLineReader(&chunks{tt.in})
+		l.Fill()
+		for _, ok := l.Next(); ok; _, ok = l.Next() {
+		}
+		if got := l.Prompt(); got != tt.prompt {
+			t.Errorf("%q: Prompt() = %v, want %v", tt.in, got, tt.prompt)
+		}
+		if tt.prompt && len(l.buf) != 0 {
+			t.Errorf("%q: prompt left %q buffered", tt.in, l.buf)
+		}
+	}
+}
+
+func TestLineReaderReset(t *testing.T) {
+	l := newLineReader(&chunks{"AT\r", "\nOK\r\n"})
+	l.Expect("AT")
+	l.Fill()
+	l.Reset()
+	l.Fill()
+	var got []string
+	for line, ok := l.Next(); ok; line, ok = l.Next() {
+		got = append(got, line)
+	}
+	// The \n after the reset is a line of its own, and no echo is expected.
+	if want := []string{"", "OK"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package modem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Directory of the UUCP lock files used by pppd, minicom and friends.
+var lockDir = "/var/lock"
+
+// PortBusyError is returned when another program holds a modem port,
+// either through a UUCP lock file or by having put it in exclusive mode.
+type PortBusyError struct {
+	Port string
+	PID  int // 0 when the holder is not known
+}
+
+func (e *PortBusyError) Error() string {
+	if e.PID == 0 {
+		return e.Port + ": port busy"
+	}
+	return fmt.Sprintf("%s: port busy (held by pid %d)", e.Port, e.PID)
+}
+
+// lockPort takes the UUCP lock file of a port. A lock file left behind by
+// a process that no longer exists is removed. When the lock directory is
+// missing or not writable, ports are used without a lock file.
+// Returns a function removing the lock.
+func lockPort(port string) (func(), error) {
+	path := filepath.Join(lockDir, "LCK.."+filepath.Base(port))
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%10d\n", os.Getpid())
+			f.Close()
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return func() {}, nil
+		}
+		pid := lockOwner(path)
+		if pid > 0 && alive(pid) {
+			return nil, &PortBusyError{Port: port, PID: pid}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, &PortBusyError{Port: port, PID: pid}
+		}
+	}
+}
+
+// lockOwner reads the pid from a lock file, in ASCII or binary form.
+func lockOwner(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+		return pid
+	}
+	if len(data) == 4 {
+		return int(data[0]) | int(data[1])<<8 | int(data[2])<<16 | int(data[3])<<24
+	}
+	return 0
+}
+
+func alive(pid int) bool {
+	err := unix.Kill(pid, 0)
+	return err == nil || err == unix.EPERM
+}
+
+// holder finds a process other than this one with port open.
+func holder(port string) int {
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	self := os.Getpid()
+	for _, fd := range fds {
+		if target, err := os.Readlink(fd); err != nil || target != port {
+			continue
+		}
+		pid, _ := strconv.Atoi(strings.Split(fd, "/")[2])
+		if pid != self {
+			return pid
+		}
+	}
+	return 0
+}
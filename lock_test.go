@@ -0,0 +1,62 @@
+package modem_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/modemtest"
+)
+
+func TestPortLock(t *testing.T) {
+	script := []modemtest.Exchange{
+		{Expect: "AT", Send: "\r\nOK\r\n"},
+		{Expect: "AT+CGSN", Send: "\r\n" + imei + "\r\n\r\nOK\r\n"},
+	}
+	tests := []struct {
+		name  string
+		owner int // pid in the lock file before the probe, 0 for none
+		busy  bool
+	}{
+		{"no lock", 0, false},
+		{"stale lock", 1 << 30, false}, // above the kernel's pid_max
+		{"held lock", os.Getpid(), true},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		modem.SetLockDir(dir)
+		l, err := modemtest.NewLoopback(script...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lock := filepath.Join(dir, "LCK.."+filepath.Base(l.Path))
+		if tt.owner != 0 {
+			if err := os.WriteFile(lock, []byte(fmt.Sprintf("%10d\n", tt.owner)), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		m := modem.New(modem.WithBaudRates(115200), modem.WithProbeBackoff(time.Second, 1))
+
+		got, err := m.ProbeImei(l.Path)
+		var busy *modem.PortBusyError
+		switch {
+		case tt.busy && (!errors.As(err, &busy) || busy.PID != tt.owner):
+			t.Errorf("%s: ProbeImei() = %q, %v, want a PortBusyError for pid %d", tt.name, got, err, tt.owner)
+		case !tt.busy && (got != imei || err != nil):
+			t.Errorf("%s: ProbeImei() = %q, %v, want %q", tt.name, got, err, imei)
+		case !tt.busy:
+			if err := l.Err(); err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+		}
+		// Only the lock of the other holder outlives the probe.
+		if _, err := os.Stat(lock); (err == nil) != tt.busy {
+			t.Errorf("%s: lock file exists %v after the probe, want %v", tt.name, err == nil, tt.busy)
+		}
+		l.Close()
+	}
+}
@@ -0,0 +1,29 @@
+package modem
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discard drops every record; it is the default until SetLogger is called.
+type discard struct{}
+
+func (discard) Enabled(context.Context, slog.Level) bool  { return false }
+func (discard) Handle(context.Context, slog.Record) error { return nil }
+func (d discard) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discard) WithGroup(string) slog.Handler           { return d }
+
+// Set the logger receiving adoption decisions, probe results and errors.
+// A nil logger silences the manager again.
+func (m *Manager) SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(discard{})
+	}
+	m.log = l
+}
+
+// Logger returns the logger set with SetLogger, for plugins to report
+// their errors alongside the manager's.
+func (m *Manager) Logger() *slog.Logger {
+	return m.log
+}
@@ -0,0 +1,327 @@
+/*
+Package mmbackend discovers and drives modems through ModemManager over
+DBus instead of udev and raw serial access, for systems where
+ModemManager already owns the modem ports.
+
+	b, err := mmbackend.New()
+	if err != nil {
+		return err
+	}
+	m := modem.New(modem.WithBackend(b))
+
+Modems are adopted with the IMEI ModemManager reports, without probing.
+SendSMS and Connect use ModemManager's Messaging and Simple interfaces.
+SendAT uses Modem.Command, which ModemManager only allows when it runs
+with --debug.
+*/
+package mmbackend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	service        = "org.freedesktop.ModemManager1"
+	rootPath       = "/org/freedesktop/ModemManager1"
+	modemIface     = service + ".Modem"
+	messagingIface = modemIface + ".Messaging"
+	simpleIface    = modemIface + ".Simple"
+	smsIface       = service + ".Sms"
+	objectManager  = "org.freedesktop.DBus.ObjectManager"
+)
+
+// ModemManager port types, MMModemPortType.
+const (
+	portNet  = 2
+	portAT   = 3
+	portQMI  = 6
+	portMBIM = 7
+)
+
+// Drivers of the control channels, as the manager tells them apart.
+var controlDrivers = map[uint32]string{portQMI: "qmi_wwan", portMBIM: "cdc_mbim"}
+
+var errUnknownModem = errors.New("Modem not known to ModemManager")
+
+// Backend is a modem.Backend and modem.PortOwner backed by ModemManager.
+type Backend struct {
+	conn *dbus.Conn
+
+	mu     sync.Mutex
+	modems map[dbus.ObjectPath]mmModem
+}
+
+// mmModem is what the backend remembers of a ModemManager modem object.
+type mmModem struct {
+	imei string
+	usb  *sysfsDevice
+	tty  string
+	nets []string
+	wdms map[string]string // driver by cdc-wdm name
+}
+
+var _ modem.Backend = (*Backend)(nil)
+var _ modem.PortOwner = (*Backend)(nil)
+
+// Connect to ModemManager on the system bus.
+func New() (*Backend, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{conn: conn, modems: make(map[dbus.ObjectPath]mmModem)}, nil
+}
+
+// Close the DBus connection.
+func (b *Backend) Close() error {
+	return b.conn.Close()
+}
+
+func (b *Backend) Run(stop <-chan struct{}, handle func(modem.Device)) error {
+	signals := make(chan *dbus.Signal, 16)
+	b.conn.Signal(signals)
+	defer b.conn.RemoveSignal(signals)
+	err := b.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(rootPath),
+		dbus.WithMatchInterface(objectManager),
+	)
+	if err != nil {
+		return err
+	}
+
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	err = b.conn.Object(service, rootPath).Call(objectManager+".GetManagedObjects", 0).Store(&objects)
+	if err != nil {
+		return err
+	}
+	for path, ifaces := range objects {
+		if props, ok := ifaces[modemIface]; ok {
+			b.added(path, props, handle)
+		}
+	}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case sig, ok := <-signals:
+			if !ok {
+				return errors.New("DBus connection closed")
+			}
+			b.signal(sig, handle)
+		}
+	}
+}
+
+func (b *Backend) signal(sig *dbus.Signal, handle func(modem.Device)) {
+	switch sig.Name {
+	case objectManager + ".InterfacesAdded":
+		var path dbus.ObjectPath
+		var ifaces map[string]map[string]dbus.Variant
+		if dbus.Store(sig.Body, &path, &ifaces) != nil {
+			return
+		}
+		if props, ok := ifaces[modemIface]; ok {
+			b.added(path, props, handle)
+		}
+	case objectManager + ".InterfacesRemoved":
+		var path dbus.ObjectPath
+		var ifaces []string
+		if dbus.Store(sig.Body, &path, &ifaces) != nil {
+			return
+		}
+		for _, i := range ifaces {
+			if i == modemIface {
+				b.removed(path, handle)
+			}
+		}
+	}
+}
+
+// added reports the ports of a new ModemManager modem to the manager:
+// its network interfaces first, then the primary AT port with the IMEI.
+func (b *Backend) added(path dbus.ObjectPath, props map[string]dbus.Variant, handle func(modem.Device)) {
+	var sysPath, primary, imei string
+	var ports [][]any
+	props["Device"].Store(&sysPath)
+	props["PrimaryPort"].Store(&primary)
+	props["EquipmentIdentifier"].Store(&imei)
+	props["Ports"].Store(&ports)
+
+	usb := &sysfsDevice{path: sysPath}
+	usb.node = usb.usbfsNode()
+	mm := mmModem{imei: imei, usb: usb, wdms: make(map[string]string)}
+	for _, p := range ports {
+		if len(p) != 2 {
+			continue
+		}
+		name, _ := p[0].(string)
+		kind, _ := p[1].(uint32)
+		switch {
+		case kind == portNet:
+			mm.nets = append(mm.nets, name)
+		case kind == portAT && mm.tty == "":
+			mm.tty = name
+		case controlDrivers[kind] != "":
+			mm.wdms[name] = controlDrivers[kind]
+		}
+	}
+	if primary != "" {
+		mm.tty = primary
+	}
+	b.mu.Lock()
+	b.modems[path] = mm
+	b.mu.Unlock()
+
+	for _, n := range mm.nets {
+		handle(&device{action: "add", subsystem: "net", name: n, usb: mm.usb})
+	}
+	for name, driver := range mm.wdms {
+		handle(&device{action: "add", subsystem: "usbmisc", name: name, node: "/dev/" + name, usb: mm.usb, driver: driver})
+	}
+	if mm.tty != "" {
+		handle(&device{action: "add", subsystem: "tty", name: mm.tty, node: "/dev/" + mm.tty, usb: mm.usb, imei: imei})
+	}
+}
+
+func (b *Backend) removed(path dbus.ObjectPath, handle func(modem.Device)) {
+	b.mu.Lock()
+	mm, ok := b.modems[path]
+	delete(b.modems, path)
+	b.mu.Unlock()
+	if ok {
+		handle(&device{action: "remove", subsystem: "usb", node: mm.usb.DevNode()})
+	}
+}
+
+// object returns the ModemManager object of the modem with d's IMEI.
+func (b *Backend) object(d modem.Modem) (dbus.BusObject, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for path, mm := range b.modems {
+		if mm.imei == d.Imei {
+			return b.conn.Object(service, path), nil
+		}
+	}
+	return nil, errUnknownModem
+}
+
+func (b *Backend) Command(d modem.Modem, cmd string, timeout time.Duration) (string, error) {
+	obj, err := b.object(d)
+	if err != nil {
+		return "", err
+	}
+	var resp string
+	err = obj.Call(modemIface+".Command", 0, cmd, uint32(timeout/time.Second)).Store(&resp)
+	return resp, err
+}
+
+func (b *Backend) SendSMS(d modem.Modem, number string, text string) error {
+	obj, err := b.object(d)
+	if err != nil {
+		return err
+	}
+	var sms dbus.ObjectPath
+	props := map[string]dbus.Variant{
+		"number": dbus.MakeVariant(number),
+		"text":   dbus.MakeVariant(text),
+	}
+	if err := obj.Call(messagingIface+".Create", 0, props).Store(&sms); err != nil {
+		return err
+	}
+	return b.conn.Object(service, sms).Call(smsIface+".Send", 0).Err
+}
+
+func (b *Backend) Connect(d modem.Modem, apn string) error {
+	obj, err := b.object(d)
+	if err != nil {
+		return err
+	}
+	var bearer dbus.ObjectPath
+	props := map[string]dbus.Variant{"apn": dbus.MakeVariant(apn)}
+	return obj.Call(simpleIface+".Connect", 0, props).Store(&bearer)
+}
+
+func (b *Backend) Disconnect(d modem.Modem) error {
+	obj, err := b.object(d)
+	if err != nil {
+		return err
+	}
+	// The root path disconnects every bearer of the modem.
+	return obj.Call(simpleIface+".Disconnect", 0, dbus.ObjectPath("/")).Err
+}
+
+// device is a port of a ModemManager modem, presented as a modem.Device.
+type device struct {
+	action    string
+	subsystem string
+	name      string
+	node      string
+	usb       *sysfsDevice
+	imei      string
+	driver    string // of control channels
+}
+
+func (d *device) Action() string          { return d.action }
+func (d *device) Subsystem() string       { return d.subsystem }
+func (d *device) SysName() string         { return d.name }
+func (d *device) DevNode() string         { return d.node }
+func (d *device) Attr(name string) string { return "" }
+func (d *device) Imei() string            { return d.imei }
+
+func (d *device) InterfaceAttr(name string) string {
+	if name == "driver" {
+		return d.driver
+	}
+	return ""
+}
+
+func (d *device) USBDevice() modem.Device {
+	if d.usb == nil || d.usb.path == "" {
+		return nil
+	}
+	return d.usb
+}
+
+// sysfsDevice is the USB device of a modem, read straight from sysfs.
+type sysfsDevice struct {
+	path string
+	node string
+}
+
+func (s *sysfsDevice) Action() string                   { return "" }
+func (s *sysfsDevice) Subsystem() string                { return "usb" }
+func (s *sysfsDevice) SysName() string                  { return filepath.Base(s.path) }
+func (s *sysfsDevice) InterfaceAttr(name string) string { return "" }
+func (s *sysfsDevice) USBDevice() modem.Device          { return nil }
+func (s *sysfsDevice) DevNode() string                  { return s.node }
+
+func (s *sysfsDevice) Attr(name string) string {
+	b, err := os.ReadFile(filepath.Join(s.path, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// usbfsNode builds the usbfs node from the bus and device numbers, which
+// is how udev names USB devices. It is computed once, while sysfs still
+// has the device.
+func (s *sysfsDevice) usbfsNode() string {
+	bus, err1 := strconv.Atoi(s.Attr("busnum"))
+	dev, err2 := strconv.Atoi(s.Attr("devnum"))
+	if err1 != nil || err2 != nil {
+		return s.path
+	}
+	return fmt.Sprintf("/dev/bus/usb/%03d/%03d", bus, dev)
+}
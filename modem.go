@@ -1,5 +1,4 @@
 /*
-
 Modem package allows you to manage usb modems connected to your computer.
 Usage example:
 
@@ -29,23 +28,41 @@ Usage example:
 		}
 		m.StopMonitor()
 	}
-
 */
 package modem
 
-import "github.com/ausrasul/udev"
-import "github.com/tarm/serial"
-import "errors"
-import "time"
-import "strings"
-const IMEILEN = 17
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // USB Modem object
 type Modem struct {
 	Net   string
 	Tty   string
 	Imei  string
-	ready int
+	Iccid string
+	Vid   string
+	Pid   string
+	Alias string    // user label, see SetAlias
+	Since time.Time // when the modem was first ready, see WithReplugGrace
+	Ports []Port    // every tty of the modem, by role
+	State State
+	// Last jamming indication, for Quectel and Telit modems. Indications
+	// are seen while the manager talks to the modem, e.g. in a
+	// TaskKeepalive task, and changes emit EventJamming.
+	Jamming JammingState
+	// Whether Connect activated a data connection that Disconnect has not
+	// ended. A replugged modem comes back without one.
+	Connected bool
+	usb       string // USB port path
+	m         *Manager
 }
 
 type filter struct {
@@ -53,28 +70,102 @@ type filter struct {
 	pid string
 }
 
+// How long a new modem is left to settle before it is probed, see
+// WithSettleDelay.
+const defaultSettle = time.Second * 5
+
 // USB Device Manager object
 type Manager struct {
-	filters     []filter
-	devices     map[string]Modem
-	stopMonitor chan bool
-	monitoring bool
-	handleAdd func(Modem)
-	handleRemove func(Modem)
-	handleUpdate func(Modem)
+	mu            sync.Mutex
+	filters       map[filter]bool
+	added         map[filter]bool         // by AddFilter, kept by Reload
+	setDevices    map[string]DeviceConfig // by SetDeviceConfig, kept by Reload
+	devices       map[string]Modem
+	stopMonitor   chan struct{}
+	monitorDone   chan struct{} // closed when the monitor goroutine ended
+	monitoring    bool          // from Monitor until StopMonitor returned
+	handleAdd     func(Modem)
+	handleRemove  func(Modem)
+	handleUpdate  func(Modem)
+	handleReject  func(RejectedDevice)
+	handleRaw     func(string, Modem, Device)
+	db            Store
+	known         map[string]Record // by IMEI, loaded from store
+	tasks         []task            // added with AddTask
+	disabledTasks map[string]bool
+	faults        Faults
+	hooks         Hooks
+	subscribers   []chan ModemEvent
+	eventBuffer   int
+	seq           uint64 // of the last event
+	outbox        []queued
+	dispatching   bool
+	drained       *sync.Cond // signalled when the outbox is empty
+	clock         Clock
+	log           *slog.Logger
+	backend       Backend
+	plugins       []Plugin
+	settle        time.Duration
+	probeSlots    chan struct{}
+	workers       sync.WaitGroup
+	portLocks     map[string]*sync.Mutex
+	held          map[string]bool
+	capture       capture
+	replugGrace   time.Duration
+	departed      map[string]departure // by IMEI
+	departGen     uint64
+	backoff       time.Duration
+	attempts      int
+	baudRates     []int
+	bauds         map[string]int
+	pending       map[string][]Port       // by USB device, classified once ready
+	overrides     map[string]DeviceConfig // by tty node
+	smsPoll       time.Duration
+	smsDelete     bool
+	optSettle     time.Duration // of WithSettleDelay, which Reload goes back to
+	optSMSPoll    time.Duration // of WithSMSPoll
+	optSMSDelete  bool          // of WithSMSDelete
+	cfg           Config
+	running       atomic.Bool
+	busySince     atomic.Int64
 }
 
-// Get new device manager instance
-func New() *Manager {
-	return &Manager{
-		devices:     make(map[string]Modem),
-		handleAdd: func(m Modem){_ = m},
-		handleRemove: func(m Modem){_ = m},
-		handleUpdate: func(m Modem){_ = m},
+// Get new device manager instance, configured by the given options.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		filters:      make(map[filter]bool),
+		added:        make(map[filter]bool),
+		devices:      make(map[string]Modem),
+		handleAdd:    func(m Modem) { _ = m },
+		handleRemove: func(m Modem) { _ = m },
+		handleUpdate: func(m Modem) { _ = m },
+		clock:        realClock{},
+		log:          slog.New(discard{}),
+		settle:       defaultSettle,
+		optSettle:    defaultSettle,
+		probeSlots:   make(chan struct{}, 1),
+		baudRates:    defaultBaudRates,
+		bauds:        make(map[string]int),
+		pending:      make(map[string][]Port),
+		overrides:    make(map[string]DeviceConfig),
+		held:         make(map[string]bool),
+		backoff:      time.Second * 5,
+		attempts:     5,
+		departed:     make(map[string]departure),
+		eventBuffer:  eventBuffer,
+	}
+	m.drained = sync.NewCond(&m.mu)
+	m.backend = udevBackend{m: m}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
-func (m *Manager) AddHandler(add func(Modem), update func(Modem), remove func(Modem)){
+// Set the functions called for added, updated and removed modems. Nil
+// ones keep the previous function. They run one at a time, in the order
+// of the events, on the goroutine delivering events, see Events.
+func (m *Manager) AddHandler(add func(Modem), update func(Modem), remove func(Modem)) {
 	if add != nil {
 		m.handleAdd = add
 	}
@@ -88,171 +179,459 @@ func (m *Manager) AddHandler(add func(Modem), update func(Modem), remove func(Mo
 
 // Add Device Filter
 func (m *Manager) AddFilter(vid string, pid string) {
-	f := filter{vid: vid, pid: pid}
-	m.filters = append(m.filters, f)
-	return
+	m.mu.Lock()
+	m.filters[filter{vid: vid, pid: pid}] = true
+	m.added[filter{vid: vid, pid: pid}] = true
+	m.mu.Unlock()
 }
 
 // Returns a hashmap of connected USB modems and their IMEI
 func (m *Manager) List() map[string]Modem {
-	devList := make(map[string]Modem)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	devList := make(map[string]Modem, len(m.devices))
 	for k, v := range m.devices {
-		if v.ready == 1 {
+		if v.State == StateReady {
 			devList[k] = v
 		}
 	}
 	return devList
 }
 
-// Start a monitor goroutine, Non blocking, you have to Unref the device manager to end it.
-func (m *Manager) Monitor() error{
+// Start the plugins and a monitor goroutine, Non blocking, call StopMonitor to end it.
+func (m *Manager) Monitor() error {
+	m.mu.Lock()
 	if m.monitoring {
+		m.mu.Unlock()
 		return errors.New("Monitor is already started")
 	}
-	m.stopMonitor = make(chan bool)
-	go m.monitor()
+	m.monitoring = true
+	m.mu.Unlock()
+	if err := m.start(); err != nil {
+		m.mu.Lock()
+		m.monitoring = false
+		m.mu.Unlock()
+		return err
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	m.mu.Lock()
+	m.stopMonitor, m.monitorDone = stop, done
+	m.mu.Unlock()
+	go func() {
+		defer close(done)
+		m.monitor(stop)
+	}()
 	return nil
 }
 
-// Stop the monitor goroutine and empty the device list.
+// start loads the store and starts the plugins.
+func (m *Manager) start() error {
+	if err := m.load(); err != nil {
+		return err
+	}
+	for i, p := range m.plugins {
+		if err := p.Start(m); err != nil {
+			for _, started := range m.plugins[:i] {
+				started.Stop()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop the monitor goroutine and the plugins, and empty the device list.
+// Returns once the monitor goroutine ended and closed the Events
+// subscriptions, so it must not be called from a handler or a hook.
 func (m *Manager) StopMonitor() error {
-	if !m.monitoring {
+	m.mu.Lock()
+	if !m.monitoring || m.stopMonitor == nil {
+		m.mu.Unlock()
 		return errors.New("Monitor already stopped.")
 	}
-	close(m.stopMonitor)
+	stop, done := m.stopMonitor, m.monitorDone
+	m.stopMonitor, m.monitorDone = nil, nil
+	m.mu.Unlock()
+	close(stop)
+	var errs []error
+	for _, p := range m.plugins {
+		errs = append(errs, p.Stop())
+	}
+	<-done
+	m.mu.Lock()
 	m.monitoring = false
-	return nil
+	m.mu.Unlock()
+	return errors.Join(errs...)
 }
 
-func (m *Manager) monitor() {
+// Reports whether the monitor goroutine is running and has not been stuck
+// on a single device event for longer than maxBusy.
+func (m *Manager) Healthy(maxBusy time.Duration) bool {
+	if !m.running.Load() {
+		return false
+	}
+	since := m.busySince.Load()
+	return since == 0 || m.clock.Now().Sub(time.Unix(0, since)) < maxBusy
+}
 
-	u := udev.NewUdev()
-	defer u.Unref()
+func (m *Manager) monitor(stop chan struct{}) {
+	m.running.Store(true)
+	defer m.running.Store(false)
+	m.log.Info("monitor started")
+	if m.smsPoll > 0 {
+		m.workers.Add(1)
+		go m.pollSMS(stop)
+	}
+	m.mu.Lock()
+	hooks := m.cfg.Exec
+	tasks := append([]task(nil), m.tasks...)
+	for _, t := range m.cfg.Tasks {
+		tasks = append(tasks, task{Task: t, run: m.action(t)})
+	}
+	m.mu.Unlock()
+	if len(hooks) > 0 {
+		m.workers.Add(1)
+		go m.runExec(stop, m.Events(), hooks)
+	}
+	if len(tasks) > 0 {
+		m.workers.Add(1)
+		go m.runTasks(stop, m.Events(), tasks)
+	}
+	err := m.backend.Run(stop, func(dev Device) {
+		m.busySince.Store(m.clock.Now().UnixNano())
+		m.readDevice(stop, dev)
+		m.busySince.Store(0)
+	})
+	if err != nil {
+		m.log.Error("backend failed", "err", err)
+	}
+	m.workers.Wait()
+	m.mu.Lock()
+	for k := range m.devices {
+		delete(m.devices, k)
+	}
+	for k := range m.departed {
+		delete(m.departed, k)
+	}
+	for k := range m.pending {
+		delete(m.pending, k)
+	}
+	m.mu.Unlock()
+	m.flush()
+	m.closeSubscribers()
+	m.log.Info("monitor stopped")
+}
 
-	e := u.NewEnumerate()
-	defer e.Unref()
+// Reads a modem properties and attributes and add/remove it from the list of devices.
+// It runs once per device event, so cheap rejections come first and every
+// attribute is read at most once.
+func (m *Manager) readDevice(stop chan struct{}, dev Device) {
+	action := dev.Action()
+	m.delayEvent(action, dev.DevNode())
 
-	mon := udev.NewMonitorFromNetlink(u, "udev")
-	defer mon.Unref()
-	
-	mon.AddFilter("tty", "")
-	mon.AddFilter("net", "")
-	mon.AddFilter("usb", "usb_device")
+	// Handle Remove action
+	if action == "remove" {
+		node := dev.DevNode()
+		m.mu.Lock()
+		modem, ok := m.devices[node]
+		delete(m.devices, node)
+		delete(m.pending, node)
+		delete(m.bauds, modem.Tty)
+		delete(m.overrides, modem.Tty)
+		for _, p := range modem.Ports {
+			delete(m.bauds, p.Node)
+			delete(m.overrides, p.Node)
+		}
+		m.mu.Unlock()
+		if ok {
+			if m.handleRaw != nil {
+				m.handleRaw(action, modem, dev)
+			}
+			m.depart(stop, node, modem)
+		}
+		return
+	}
 
-	err := mon.EnableReceiving()
-	if err != nil {
+	// Filter unrelated devices
+	subsystem := dev.Subsystem()
+	if subsystem != "tty" && subsystem != "net" && subsystem != "usbmisc" {
+		m.reject(dev, RejectedDevice{Reason: RejectSubsystem})
 		return
 	}
 
-	e.AddMatchSubsystem("tty")
-	e.AddMatchSubsystem("net")
-	e.ScanDevices()
+	usbDev := dev.USBDevice()
+	if usbDev == nil {
+		m.reject(dev, RejectedDevice{Reason: RejectNotUSB})
+		return
+	}
+	vid := usbDev.Attr("idVendor")
+	pid := usbDev.Attr("idProduct")
+	m.mu.Lock()
+	match := m.filters[filter{vid: vid, pid: pid}]
+	m.mu.Unlock()
+	if !match {
+		m.log.Debug("no filter match", "vid", vid, "pid", pid)
+		m.reject(dev, RejectedDevice{Vid: vid, Pid: pid, Reason: RejectFilter})
+		return
+	}
 
-	for device := e.First(); !device.IsNil(); device = device.Next() {
-		path := device.Name()
-		dev := u.DeviceFromSysPath(path)
-		m.readDevice(dev)
+	key := usbDev.DevNode()
+	m.mu.Lock()
+	d := m.devices[key]
+	m.mu.Unlock()
+	d.usb = usbDev.SysName()
+	d.Vid, d.Pid = vid, pid
+	if m.handleRaw != nil {
+		d.m = m
+		m.handleRaw(action, d, dev)
 	}
-	for {
-		select {
-		case <-m.stopMonitor:
-			for k := range m.devices{
-				delete(m.devices, k)
-			}
-			break
+
+	if subsystem == "net" {
+		d.Net = dev.SysName()
+		m.log.Debug("net interface adopted", "usb", key, "net", d.Net)
+		m.store(key, d)
+		return
+	}
+	if subsystem == "usbmisc" {
+		m.control(key, d, dev, RejectedDevice{Vid: vid, Pid: pid})
+		return
+	}
+	if id, ok := dev.(IdentifiedDevice); ok {
+		d.Tty = dev.DevNode()
+		d.Imei = id.Imei()
+		d.State = StateReady
+		m.mu.Lock()
+		action = m.arriveLocked(&d, action)
+		m.mu.Unlock()
+		m.log.Info("modem ready", "usb", key, "tty", d.Tty, "imei", d.Imei, "action", action)
+		m.store(key, d)
+		m.publish(action, d)
+		return
+	}
+	c, _ := m.deviceConfig(vid, pid, usbDev)
+	m.mu.Lock()
+	m.overrides[dev.DevNode()] = c
+	m.mu.Unlock()
+	endpoints := "03"
+	if c.CommandEndpoints != "" {
+		endpoints = c.CommandEndpoints
+	}
+	iface := dev.InterfaceAttr("bInterfaceNumber")
+	role, known := tableRole(vid, pid, iface)
+	if c.CommandInterface != "" {
+		// The configured interface replaces the table's command port.
+		if iface == c.CommandInterface {
+			role, known = PortCommand, true
+		} else if role == PortCommand {
+			known = false
+		}
+	}
+	command := known && role == PortCommand ||
+		!known && c.CommandInterface == "" && dev.InterfaceAttr("bNumEndpoints") == endpoints
+	if !command {
+		m.log.Debug("tty skipped, not a command port", "usb", key, "tty", dev.SysName())
+		m.reject(dev, RejectedDevice{Vid: vid, Pid: pid, Reason: RejectEndpoints})
+		m.store(key, d)
+		switch {
+		case known:
+			m.setPort(key, Port{Node: dev.DevNode(), Role: role, Interface: iface})
+		case diagInterface(dev):
+			m.log.Debug("diagnostics port found", "usb", key, "tty", dev.SysName())
+			m.setPort(key, Port{Node: dev.DevNode(), Role: PortDiag, Interface: iface})
 		default:
-			d := mon.ReceiveDevice()
-			if !d.IsNil() {
-				m.readDevice(d)
-			} else {
-				time.Sleep(time.Second)
-			}
+			m.unclassified(stop, key, Port{Node: dev.DevNode(), Interface: iface})
 		}
+		return
 	}
-	// then hold a list for usb plug ports status
-	// map usbplug port number and status to the list.
+	if d.State == StateFailed {
+		m.log.Debug("tty skipped, probing gave up", "usb", key, "tty", dev.SysName())
+		return
+	}
+	// Register the modem before probing so a remove during the probe wins.
+	m.store(key, d)
+	r := RejectedDevice{Name: dev.SysName(), Node: dev.DevNode(), Subsystem: subsystem, Vid: vid, Pid: pid}
+	m.workers.Add(1)
+	go m.probe(stop, key, action, r, Port{Node: dev.DevNode(), Role: PortCommand, Interface: iface}, known)
 }
 
-// Reads a modem properties and attributes and add/remove it from the list of devices.
-func (m *Manager) readDevice(dev *udev.Device) {
-	action := dev.Action()
-	
-	// Handle Remove action
-	if action == "remove" {
-		modem, ok := m.devices[dev.DevNode()]
-		if !ok {
-			return
+// wait takes a probe slot and waits for a new modem to settle. It reports
+// false if the monitor stopped meanwhile, otherwise the caller must
+// release the slot.
+func (m *Manager) wait(stop chan struct{}, action string, node string) bool {
+	select {
+	case m.probeSlots <- struct{}{}:
+	case <-stop:
+		return false
+	}
+	// Delay if add action
+	if action == "add" {
+		select {
+		case <-m.clock.After(m.settleFor(node)):
+		case <-stop:
+			<-m.probeSlots
+			return false
 		}
-		m.handleRemove(modem)
-		delete(m.devices, dev.DevNode())
+	}
+	return true
+}
+
+// probe waits for the modem to settle, queries its IMEI and publishes the
+// result. At most the configured number of probes run at once. Unless the
+// port is known to be the command port, the first port answering becomes
+// it and later ones are data ports.
+func (m *Manager) probe(stop chan struct{}, key string, action string, r RejectedDevice, port Port, known bool) {
+	defer m.workers.Done()
+	if !m.wait(stop, action, r.Node) {
 		return
 	}
+	defer func() { <-m.probeSlots }()
 
-	fileDescriptor := dev.SysName()
-	originalDevNode := dev.DevNode()
-	originalSubSys := dev.Subsystem()
-	originalEPnum := dev.Parent().Parent().SysAttrValue("bNumEndpoints")
+	node := r.Node
+	if m.isHeld(node) {
+		m.log.Debug("probe skipped, port is held", "usb", key, "tty", node)
+		return
+	}
+	imei, err := m.probeImei(stop, key, node)
+	if err == errStopped {
+		return
+	}
+	if err != nil && !errors.Is(err, ErrPortHeld) {
+		if alt, altImei, ok := m.fallback(key, node); ok {
+			m.log.Info("command port found on another port", "usb", key, "failed", node, "tty", alt.Node)
+			m.setPort(key, Port{Node: node, Role: PortDiag, Interface: port.Interface})
+			node, port, imei, err, known = alt.Node, alt, altImei, nil, true
+		}
+	}
 
-	// Filter unrelated devices
-	if originalSubSys != "tty" && originalSubSys != "net" {
+	m.mu.Lock()
+	d, ok := m.devices[key]
+	command := known || d.Tty == "" || d.Tty == node
+	if ok && err == nil && command {
+		d.Tty = node
+		m.devices[key] = d
+	}
+	m.mu.Unlock()
+	if ok && err == nil && !command {
+		port.Role = PortData
+		m.log.Debug("data port found", "usb", key, "tty", node)
+		m.setPort(key, port)
 		return
 	}
+	var iccid string
+	if err == nil {
+		m.setPort(key, port)
+		iccid = m.setup(node, imei)
+	}
 
-	dev = dev.ParentWithSubsystemDevType("usb", "usb_device")
-	if dev.IsNil() {
+	m.mu.Lock()
+	d, ok = m.devices[key]
+	if ok && err == nil {
+		d.Imei = imei
+		d.Iccid = iccid
+		d.State = StateReady
+		d.Alias = m.aliasLocked(d)
+		action = m.arriveLocked(&d, action)
+		m.devices[key] = d
+		m.classifyPendingLocked(stop, key)
+	}
+	if ok {
+		// Queued with the change, so a remove of the modem comes after.
+		m.publishLocked(action, d)
+	}
+	m.mu.Unlock()
+	if !ok {
 		return
 	}
+	if err == nil {
+		m.log.Info("modem ready", "usb", key, "tty", node, "imei", imei, "action", action)
+	} else {
+		var perm *PermissionError
+		if errors.As(err, &perm) {
+			m.log.Error("IMEI probe failed", "usb", key, "tty", node, "err", err)
+		} else {
+			m.log.Warn("IMEI probe failed", "usb", key, "tty", node, "err", err)
+		}
+		if m.handleReject != nil {
+			r.Reason = RejectProbe
+			r.Err = err
+			m.handleReject(r)
+		}
+	}
+	if err != nil && !errors.Is(err, ErrPortHeld) {
+		m.giveUp(key, node)
+	}
+}
 
-	vid := dev.SysAttrValue("idVendor")
-	pid := dev.SysAttrValue("idProduct")
-	for _, f := range m.filters {
-		if vid == f.vid && pid == f.pid {
-			d := m.devices[dev.DevNode()]
-			if originalSubSys == "net" {
-				d.Net = fileDescriptor
-			}
-			if originalSubSys == "tty" && originalEPnum == "03" {
-				// Delay if add action
-				if action == "add" {
-					time.Sleep(time.Second * 5)
-				}
-				imei, err := getImei(originalDevNode)
-				if err == nil {
-					d.Tty = originalDevNode
-					d.Imei = imei
-					d.ready = 1
-				}
-				if (action != "update"){
-					m.handleAdd(d)
-				} else {
-					m.handleUpdate(d)
-				}
+// store saves the modem state under its USB device node.
+func (m *Manager) store(key string, d Modem) {
+	d.m = m
+	m.mu.Lock()
+	d.Alias = m.aliasLocked(d)
+	m.devices[key] = d
+	m.mu.Unlock()
+}
 
-			}
-			m.devices[dev.DevNode()] = d
-		}
+// publish queues a modem for the handler registered for the udev action
+// and for the Events subscribers.
+func (m *Manager) publish(action string, d Modem) {
+	m.mu.Lock()
+	m.publishLocked(action, d)
+	m.mu.Unlock()
+}
+
+// publishLocked is publish with m.mu held.
+func (m *Manager) publishLocked(action string, d Modem) {
+	ev := ModemEvent{Type: EventAdd, Modem: d}
+	switch action {
+	case "remove":
+		ev.Type = EventRemove
+	case "update":
+		ev.Type = EventUpdate
+	default:
+		action = "add"
 	}
+	m.queueLocked(queued{ev: ev, action: action})
+}
+
+// emit queues an event for the Events subscribers and the OnEvent hook.
+func (m *Manager) emit(ev ModemEvent) {
+	m.mu.Lock()
+	m.queueLocked(queued{ev: ev})
+	m.mu.Unlock()
+}
+
+// emitReceived emits an event and waits for its delivery. It reports
+// whether the event was received, by the OnEvent hook or by at least one
+// subscriber, and missed by no subscriber.
+func (m *Manager) emitReceived(ev ModemEvent) bool {
+	done := make(chan bool, 1)
+	m.mu.Lock()
+	m.queueLocked(queued{ev: ev, done: done})
+	m.mu.Unlock()
+	return <-done
 }
 
+var imeiRe = regexp.MustCompile(`^[0-9]{15}$`)
+
 // Get IMEI from a modem using AT command
-func getImei(port string) (imei string, err error) {
-	c := &serial.Config{Name: port, Baud: 115200, ReadTimeout: time.Millisecond * 10}
-	s, err := serial.OpenPort(c)
-	if err != nil {
-		return
-	}
-	n, err := s.Write([]byte("AT+CGSN\r\n"))
+func (m *Manager) getImei(port string) (imei string, err error) {
+	m.hooks.probeStart(port)
+	start := m.clock.Now()
+	defer func() { m.hooks.probeEnd(port, imei, err, m.clock.Now().Sub(start)) }()
+
+	p, err := m.openAT(context.Background(), port, "")
 	if err != nil {
 		return
 	}
-	buf := make([]byte, 128)
-	s.Read(buf)
-	n, err = s.Read(buf)
+	defer p.Close()
+	resp, err := p.Command("AT+CGSN", commandTimeout)
 	if err != nil {
 		return
 	}
-	if n != 25 {
+	// Some modems prefix the IMEI with +CGSN: and quote it.
+	resp = strings.Trim(strings.TrimSpace(strings.TrimPrefix(resp, "+CGSN:")), `"`)
+	if !imeiRe.MatchString(resp) {
 		return "", errors.New("Invalid Imei")
 	}
-	return strings.Trim(string(buf[:IMEILEN]), "\r\n "), nil
+	return resp, nil
 }
@@ -0,0 +1,76 @@
+package modem
+
+import (
+	"fmt"
+	"testing"
+)
+
+// hub returns a manager filtering 32 modem models.
+func hub() *Manager {
+	m := New(WithReplugGrace(0))
+	for i := 0; i < 32; i++ {
+		m.AddFilter("12d1", fmt.Sprintf("%04x", 0x1000+i))
+	}
+	return m
+}
+
+func BenchmarkReadDevice(b *testing.B) {
+	other := &fakeDevice{subsystem: "usb", node: "/dev/bus/usb/001/009", attrs: map[string]string{"idVendor": "046d", "idProduct": "c52b"}}
+	modemUSB := &fakeDevice{subsystem: "usb", name: "1-1", node: "/dev/bus/usb/001/002", attrs: map[string]string{"idVendor": "12d1", "idProduct": "1001"}}
+	events := []struct {
+		name string
+		dev  Device
+	}{
+		{"OtherSubsystem", &fakeDevice{action: "change", subsystem: "block", name: "sda"}},
+		{"NotUSB", &fakeDevice{action: "change", subsystem: "tty", name: "ttyS0", node: "/dev/ttyS0"}},
+		{"NoFilterMatch", &fakeDevice{action: "change", subsystem: "tty", name: "ttyUSB9", node: "/dev/ttyUSB9", usb: other}},
+		{"NetInterface", &fakeDevice{action: "change", subsystem: "net", name: "wwan0", usb: modemUSB}},
+	}
+	for _, e := range events {
+		b.Run(e.name, func(b *testing.B) {
+			m := hub()
+			stop := make(chan struct{})
+			defer close(stop)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.readDevice(stop, e.dev)
+			}
+		})
+	}
+}
+
+func BenchmarkPublish(b *testing.B) {
+	m := hub()
+	events := m.Events()
+	done := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(done)
+	}()
+	usb, tty := usbModem("1", "490154203237518")
+	d := Modem{Tty: tty.node, Imei: tty.imei, Vid: "12d1", Pid: "1001", State: StateReady, usb: usb.name}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.publish("update", d)
+	}
+	m.flush()
+	b.StopTimer()
+	m.closeSubscribers()
+	<-done
+}
+
+func BenchmarkAddRemove(b *testing.B) {
+	_, tty := usbModem("1", "490154203237518")
+	remove := unplug(tty.usb)
+	m := hub()
+	m.AddFilter("12d1", "1001")
+	stop := make(chan struct{})
+	defer close(stop)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.readDevice(stop, tty)
+		m.readDevice(stop, remove)
+	}
+	m.flush()
+}
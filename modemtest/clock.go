@@ -0,0 +1,48 @@
+package modemtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+)
+
+var _ modem.Clock = (*Clock)(nil)
+
+// Clock is a fake modem.Clock on which sleeping takes no real time: every
+// Sleep or After moves the clock forward by the requested duration.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Create a fake clock starting at now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Advance(d)
+	return ch
+}
+
+// Move the clock forward by d and return the new time.
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return c.now
+}
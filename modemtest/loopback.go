@@ -0,0 +1,180 @@
+/*
+Package modemtest provides a fake modem for testing code that talks AT
+commands over a serial port.
+
+A Loopback is a pseudo terminal with a scripted responder on the master
+side. Code under test opens Loopback.Path like any ttyUSB node:
+
+	l, err := modemtest.NewLoopback(
+		modemtest.Exchange{Expect: "AT+CGSN", Send: "\r\n490154203237518\r\n\r\nOK\r\n"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	// ... open l.Path and send AT+CGSN ...
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+*/
+package modemtest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// Exchange is one step of a script: when the responder reads the line
+// Expect it writes Send back verbatim.
+type Exchange struct {
+	Expect string
+	Send   string
+}
+
+// Loopback is a pseudo terminal answering AT commands from a script.
+type Loopback struct {
+	// Device node of the terminal end, to be opened by the code under test.
+	Path string
+
+	master *os.File
+	slave  *os.File
+	script []Exchange
+	done   chan struct{}
+
+	mu  sync.Mutex
+	pos int
+	err error
+}
+
+// Create a loopback answering the given exchanges in order.
+func NewLoopback(script ...Exchange) (*Loopback, error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	master := os.NewFile(uintptr(fd), "/dev/ptmx")
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, err
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+	path := "/dev/pts/" + strconv.Itoa(n)
+	// Keep a handle on the terminal end so the master does not see EIO
+	// whenever the code under test closes its own.
+	slave, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+	if err := makeRaw(int(slave.Fd())); err != nil {
+		slave.Close()
+		master.Close()
+		return nil, err
+	}
+	l := &Loopback{
+		Path:   path,
+		master: master,
+		slave:  slave,
+		script: script,
+		done:   make(chan struct{}),
+	}
+	go l.respond()
+	return l, nil
+}
+
+// makeRaw disables echo and line editing, like a real modem port.
+func makeRaw(fd int) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB
+	t.Cflag |= unix.CS8
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS, t)
+}
+
+func (l *Loopback) respond() {
+	defer close(l.done)
+	s := bufio.NewScanner(l.master)
+	s.Split(scanLines)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		l.mu.Lock()
+		var reply string
+		switch {
+		case l.pos >= len(l.script):
+			l.fail(fmt.Errorf("Unexpected command %q after end of script", line))
+			reply = "\r\nERROR\r\n"
+		case l.script[l.pos].Expect != line:
+			l.fail(fmt.Errorf("Step %d: expected %q, got %q", l.pos, l.script[l.pos].Expect, line))
+			reply = "\r\nERROR\r\n"
+		default:
+			reply = l.script[l.pos].Send
+			l.pos++
+		}
+		l.mu.Unlock()
+		if _, err := l.master.WriteString(reply); err != nil {
+			return
+		}
+	}
+}
+
+// fail records the first script violation. l.mu must be held.
+func (l *Loopback) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// scanLines splits on \r or \n, the way modems terminate commands.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	for i, b := range data {
+		if b == '\r' || b == '\n' {
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Returns the first mismatch between the script and what was received,
+// or an error naming the first exchange that never happened.
+func (l *Loopback) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+	if l.pos < len(l.script) {
+		return fmt.Errorf("Step %d: %q was never received", l.pos, l.script[l.pos].Expect)
+	}
+	return nil
+}
+
+// Close both ends of the terminal and stop the responder.
+func (l *Loopback) Close() error {
+	err := l.master.Close()
+	l.slave.Close()
+	<-l.done
+	return err
+}
@@ -0,0 +1,141 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ausrasul/modem"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Device groups a modem's entities in Home Assistant.
+type Device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	SerialNumber string   `json:"serial_number"`
+}
+
+// Discovery is the config payload of a Home Assistant entity.
+type Discovery struct {
+	Name              string `json:"name"`
+	UniqueID          string `json:"unique_id"`
+	Device            Device `json:"device"`
+	AvailabilityTopic string `json:"availability_topic"`
+	StateTopic        string `json:"state_topic,omitempty"`
+	ValueTemplate     string `json:"value_template,omitempty"`
+	CommandTopic      string `json:"command_topic,omitempty"`
+	DeviceClass       string `json:"device_class,omitempty"`
+	StateClass        string `json:"state_class,omitempty"`
+	Unit              string `json:"unit_of_measurement,omitempty"`
+	Icon              string `json:"icon,omitempty"`
+}
+
+// DiscoveryTopic is where the config of a modem's entity is published,
+// e.g. homeassistant/sensor/modem_<imei>/signal/config.
+func DiscoveryTopic(prefix string, component string, d modem.Modem, object string) string {
+	return prefix + "/" + component + "/" + nodeID(d) + "/" + object + "/config"
+}
+
+func nodeID(d modem.Modem) string {
+	return "modem_" + d.Imei
+}
+
+// discover announces a modem when it first becomes ready and keeps its
+// availability and connectivity state up to date.
+func (b *bridge) discover(ev modem.ModemEvent) {
+	d := ev.Modem
+	switch ev.Type {
+	case modem.EventAdd, modem.EventUpdate:
+		b.mu.Lock()
+		if !b.known[d.Imei] {
+			b.announce(d)
+			b.known[d.Imei] = true
+		}
+		b.mu.Unlock()
+		b.retain(b.cfg.AvailabilityTopic, d, "online")
+	case modem.EventRemove:
+		b.retain(b.cfg.StateTopic, d, "OFF")
+		b.retain(b.cfg.AvailabilityTopic, d, "offline")
+	case modem.EventConnect:
+		b.retain(b.cfg.StateTopic, d, "ON")
+	case modem.EventDisconnect:
+		b.retain(b.cfg.StateTopic, d, "OFF")
+	}
+}
+
+// announce publishes the discovery configs of a modem's entities and
+// subscribes to its notify topic.
+func (b *bridge) announce(d modem.Modem) {
+	name := "Modem " + d.Imei
+	if d.Alias != "" {
+		name = d.Alias
+	}
+	dev := Device{Identifiers: []string{nodeID(d)}, Name: name, SerialNumber: d.Imei}
+	entity := func(name string, object string) Discovery {
+		return Discovery{
+			Name:              name,
+			UniqueID:          nodeID(d) + "_" + object,
+			Device:            dev,
+			AvailabilityTopic: Topic(b.cfg.AvailabilityTopic, d),
+		}
+	}
+
+	signal := entity("Signal", "signal")
+	signal.StateTopic = Topic(b.cfg.SignalTopic, d)
+	signal.ValueTemplate = "{{ value_json.dbm }}"
+	signal.DeviceClass = "signal_strength"
+	signal.StateClass = "measurement"
+	signal.Unit = "dBm"
+	b.config("sensor", d, "signal", signal)
+
+	conn := entity("Connectivity", "connectivity")
+	conn.StateTopic = Topic(b.cfg.StateTopic, d)
+	conn.DeviceClass = "connectivity"
+	b.config("binary_sensor", d, "connectivity", conn)
+
+	sms := entity("SMS", "sms")
+	sms.CommandTopic = Topic(b.cfg.NotifyTopic, d)
+	sms.Icon = "mdi:message-text"
+	b.config("notify", d, "sms", sms)
+
+	b.subscribe(d.Imei)
+}
+
+// resubscribe subscribes again to the notify topics of the modems already
+// announced, as the broker forgets them when the client reconnects.
+func (b *bridge) resubscribe(mqtt.Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for imei := range b.known {
+		b.subscribe(imei)
+	}
+}
+
+// subscribe sends the messages on a modem's notify topic as SMS.
+func (b *bridge) subscribe(imei string) {
+	topic := Topic(b.cfg.NotifyTopic, modem.Modem{Imei: imei})
+	b.client.Subscribe(topic, b.cfg.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+		number, text, ok := strings.Cut(strings.TrimSpace(string(msg.Payload())), " ")
+		if !ok || number == "" {
+			return
+		}
+		go func() {
+			if err := b.m.SendSMS(imei, number, strings.TrimSpace(text)); err != nil {
+				b.m.Logger().Warn("mqtt notify SMS not sent", "imei", imei, "err", err)
+			}
+		}()
+	})
+}
+
+func (b *bridge) config(component string, d modem.Modem, object string, v Discovery) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	b.client.Publish(DiscoveryTopic(b.cfg.DiscoveryPrefix, component, d, object), b.cfg.QoS, true, payload)
+}
+
+// retain publishes a retained plain payload on the topic template expanded for d.
+func (b *bridge) retain(topic string, d modem.Modem, payload string) {
+	b.client.Publish(Topic(topic, d), b.cfg.QoS, true, payload)
+}
@@ -0,0 +1,239 @@
+/*
+Package mqttbridge publishes modem lifecycle events, signal samples and
+received SMS from a modem.Manager to an MQTT broker.
+
+	m := modem.New(
+		modem.WithSMSPoll(30*time.Second),
+		modem.WithPlugins(mqttbridge.Plugin(mqttbridge.Config{
+			Broker:         "tcp://broker.local:1883",
+			SignalInterval: time.Minute,
+		})),
+	)
+
+Payloads are JSON. Topics are templates in which {imei} is replaced by the
+modem's IMEI, or by the name of its tty for modems that never gave one,
+as in gaveup events.
+
+With Discovery set, the bridge also announces each modem to Home Assistant
+under DiscoveryPrefix: a signal strength sensor fed from SignalTopic, a
+connectivity binary sensor that follows Connect and Disconnect, and a
+notify entity that sends SMS. A notify message is sent as a text to the
+number in its first word, so "+46701234567 Hello" sends "Hello" to
++46701234567. Entities go unavailable while their modem is unplugged.
+*/
+package mqttbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Default topic templates.
+const (
+	DefaultEventTopic  = "modem/{imei}/event"
+	DefaultSignalTopic = "modem/{imei}/signal"
+	DefaultSMSTopic    = "modem/{imei}/sms"
+
+	DefaultDiscoveryPrefix   = "homeassistant"
+	DefaultNotifyTopic       = "modem/{imei}/notify"
+	DefaultStateTopic        = "modem/{imei}/connectivity"
+	DefaultAvailabilityTopic = "modem/{imei}/availability"
+)
+
+// Config of the bridge. Only Broker is required.
+type Config struct {
+	Broker   string // e.g. tcp://localhost:1883
+	ClientID string
+	Username string
+	Password string
+	QoS      byte
+
+	EventTopic  string
+	SignalTopic string
+	SMSTopic    string
+
+	// How often to publish the signal quality of each ready modem.
+	// Zero disables signal samples.
+	SignalInterval time.Duration
+
+	// Publish Home Assistant discovery messages, see the package doc.
+	Discovery         bool
+	DiscoveryPrefix   string
+	NotifyTopic       string // SMS to send, "<number> <text>"
+	StateTopic        string // ON while a data connection is active
+	AvailabilityTopic string // online or offline
+}
+
+// Published on EventTopic.
+type Event struct {
+	Type  string `json:"type"`
+	Imei  string `json:"imei"`
+	Alias string `json:"alias,omitempty"`
+	Tty   string `json:"tty"`
+	Net   string `json:"net"`
+	Seq   uint64 `json:"seq"` // see modem.ModemEvent
+}
+
+// Published on SignalTopic.
+type Signal struct {
+	RSSI int `json:"rssi"`
+	BER  int `json:"ber"`
+	DBm  int `json:"dbm,omitempty"`
+}
+
+// Published on SMSTopic.
+type SMS struct {
+	Sender string `json:"sender"`
+	Time   string `json:"time"`
+	Text   string `json:"text"`
+	Seq    uint64 `json:"seq"` // of the EventSMS
+}
+
+type bridge struct {
+	cfg    Config
+	client mqtt.Client
+	m      *modem.Manager
+	events <-chan modem.ModemEvent
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	known map[string]bool // IMEIs announced to Home Assistant
+}
+
+// Plugin forwards the manager's events to the broker while it is monitoring.
+func Plugin(cfg Config) modem.Plugin {
+	if cfg.EventTopic == "" {
+		cfg.EventTopic = DefaultEventTopic
+	}
+	if cfg.SignalTopic == "" {
+		cfg.SignalTopic = DefaultSignalTopic
+	}
+	if cfg.SMSTopic == "" {
+		cfg.SMSTopic = DefaultSMSTopic
+	}
+	if cfg.DiscoveryPrefix == "" {
+		cfg.DiscoveryPrefix = DefaultDiscoveryPrefix
+	}
+	if cfg.NotifyTopic == "" {
+		cfg.NotifyTopic = DefaultNotifyTopic
+	}
+	if cfg.StateTopic == "" {
+		cfg.StateTopic = DefaultStateTopic
+	}
+	if cfg.AvailabilityTopic == "" {
+		cfg.AvailabilityTopic = DefaultAvailabilityTopic
+	}
+	return &bridge{cfg: cfg}
+}
+
+func (b *bridge) Start(m *modem.Manager) error {
+	if b.cfg.Broker == "" {
+		return errors.New("No MQTT broker configured")
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(b.cfg.Broker).
+		SetClientID(b.cfg.ClientID).
+		SetUsername(b.cfg.Username).
+		SetPassword(b.cfg.Password).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(b.resubscribe)
+	b.m = m
+	b.known = make(map[string]bool)
+	b.client = mqtt.NewClient(opts)
+	if t := b.client.Connect(); t.Wait() && t.Error() != nil {
+		return t.Error()
+	}
+	b.stop = make(chan struct{})
+	b.events = m.Events()
+	b.wg.Add(1)
+	go b.forward()
+	if b.cfg.SignalInterval > 0 {
+		b.wg.Add(1)
+		go b.sample()
+	}
+	return nil
+}
+
+func (b *bridge) Stop() error {
+	close(b.stop)
+	b.m.Unsubscribe(b.events)
+	b.wg.Wait()
+	b.mu.Lock()
+	for imei := range b.known {
+		b.retain(b.cfg.AvailabilityTopic, modem.Modem{Imei: imei}, "offline")
+	}
+	b.mu.Unlock()
+	b.client.Disconnect(250)
+	return nil
+}
+
+func (b *bridge) forward() {
+	defer b.wg.Done()
+	for ev := range b.events {
+		// Modems removed before they answered have neither.
+		if ev.Modem.Imei == "" && ev.Modem.Tty == "" {
+			continue
+		}
+		if b.cfg.Discovery && ev.Modem.Imei != "" {
+			b.discover(ev)
+		}
+		if ev.Type == modem.EventSMS {
+			b.publish(b.cfg.SMSTopic, ev.Modem, SMS{Sender: ev.SMS.Sender, Time: ev.SMS.Time, Text: ev.SMS.Text, Seq: ev.Seq})
+			continue
+		}
+		b.publish(b.cfg.EventTopic, ev.Modem, Event{
+			Type:  ev.Type.String(),
+			Imei:  ev.Modem.Imei,
+			Alias: ev.Modem.Alias,
+			Tty:   ev.Modem.Tty,
+			Net:   ev.Modem.Net,
+			Seq:   ev.Seq,
+		})
+	}
+}
+
+func (b *bridge) sample() {
+	defer b.wg.Done()
+	t := time.NewTicker(b.cfg.SignalInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-t.C:
+		}
+		for _, d := range b.m.List() {
+			s, err := b.m.Signal(d.Imei)
+			if err != nil {
+				continue
+			}
+			b.publish(b.cfg.SignalTopic, d, Signal{RSSI: s.RSSI, BER: s.BER, DBm: s.DBm()})
+		}
+	}
+}
+
+// publish sends v as JSON on the topic template expanded for d.
+func (b *bridge) publish(topic string, d modem.Modem, v any) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	b.client.Publish(Topic(topic, d), b.cfg.QoS, false, payload)
+}
+
+// Topic expands a topic template for d.
+func Topic(template string, d modem.Modem) string {
+	id := d.Imei
+	if id == "" {
+		id = path.Base(d.Tty)
+	}
+	return strings.ReplaceAll(template, "{imei}", id)
+}
@@ -0,0 +1,197 @@
+/*
+Package networkmanager hands the data connections started by
+modem.Manager.Connect to NetworkManager, so the rest of the system (DNS,
+routing priorities, connectivity checks) treats them like any other
+connection.
+
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return err
+	}
+	m := modem.New(modem.WithPlugins(networkmanager.Plugin(conn)))
+
+After a successful Connect the plugin activates a volatile ethernet
+profile named "modem <IMEI>" on the modem's network interface, with DHCP
+for IPv4 and automatic IPv6. NetworkManager forgets the profile when it
+is deactivated, which the plugin does on Disconnect and when the modem
+is removed. Events that a busy subscriber misses are caught up with every
+30 seconds from the Connected state in Manager.List, which also retries
+failed activations. Errors go to the manager's logger. Modems driven
+through ModemManager don't need this plugin.
+*/
+package networkmanager
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	service = "org.freedesktop.NetworkManager"
+	path    = dbus.ObjectPath("/org/freedesktop/NetworkManager")
+	iface   = service
+)
+
+// How often the active connections are reconciled with Manager.List.
+const reconcileInterval = 30 * time.Second
+
+// connection is a connection activated for a modem.
+type connection struct {
+	active dbus.ObjectPath
+	net    string
+}
+
+type plugin struct {
+	conn   *dbus.Conn
+	m      *modem.Manager
+	log    *slog.Logger
+	events <-chan modem.ModemEvent
+	done   chan struct{}
+
+	mu     sync.Mutex
+	active map[string]connection // by IMEI
+}
+
+// Plugin registers the manager's data connections with NetworkManager on conn.
+func Plugin(conn *dbus.Conn) modem.Plugin {
+	return &plugin{conn: conn}
+}
+
+func (p *plugin) Start(m *modem.Manager) error {
+	p.m = m
+	p.log = m.Logger()
+	p.active = make(map[string]connection)
+	p.events = m.Events()
+	p.done = make(chan struct{})
+	go p.run()
+	return nil
+}
+
+func (p *plugin) Stop() error {
+	p.m.Unsubscribe(p.events)
+	<-p.done
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for imei := range p.active {
+		errs = append(errs, p.deactivate(imei))
+	}
+	return errors.Join(errs...)
+}
+
+func (p *plugin) run() {
+	defer close(p.done)
+	t := time.NewTicker(reconcileInterval)
+	defer t.Stop()
+	for {
+		select {
+		case ev, ok := <-p.events:
+			if !ok {
+				return
+			}
+			p.handle(ev)
+		case <-t.C:
+			p.reconcile()
+		}
+	}
+}
+
+func (p *plugin) handle(ev modem.ModemEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch ev.Type {
+	case modem.EventConnect:
+		if ev.Modem.Net != "" {
+			p.deactivateLogged(ev.Modem.Imei)
+			p.activateLogged(ev.Modem)
+		}
+	case modem.EventDisconnect, modem.EventRemove:
+		p.deactivateLogged(ev.Modem.Imei)
+	}
+}
+
+// reconcile activates the connected modems without a connection and
+// deactivates the connections of modems no longer connected, or now on
+// another interface.
+func (p *plugin) reconcile() {
+	want := make(map[string]modem.Modem)
+	for _, d := range p.m.List() {
+		if d.Connected && d.Net != "" {
+			want[d.Imei] = d
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for imei, c := range p.active {
+		if d, ok := want[imei]; !ok || d.Net != c.net {
+			p.deactivateLogged(imei)
+		}
+	}
+	for imei, d := range want {
+		if _, ok := p.active[imei]; !ok {
+			p.activateLogged(d)
+		}
+	}
+}
+
+// activateLogged activates a profile for d and logs a failure.
+// p.mu must be held.
+func (p *plugin) activateLogged(d modem.Modem) {
+	if err := p.activate(d); err != nil {
+		p.log.Warn("networkmanager activation failed", "imei", d.Imei, "net", d.Net, "err", err)
+	}
+}
+
+// deactivateLogged deactivates the connection of a modem and logs a
+// failure. p.mu must be held.
+func (p *plugin) deactivateLogged(imei string) {
+	if err := p.deactivate(imei); err != nil {
+		p.log.Warn("networkmanager deactivation failed", "imei", imei, "err", err)
+	}
+}
+
+// activate adds and activates a volatile profile for d's interface.
+// p.mu must be held.
+func (p *plugin) activate(d modem.Modem) error {
+	nm := p.conn.Object(service, path)
+	var device dbus.ObjectPath
+	if err := nm.Call(iface+".GetDeviceByIpIface", 0, d.Net).Store(&device); err != nil {
+		return err
+	}
+	settings := map[string]map[string]dbus.Variant{
+		"connection": {
+			"id":             dbus.MakeVariant("modem " + d.Imei),
+			"type":           dbus.MakeVariant("802-3-ethernet"),
+			"interface-name": dbus.MakeVariant(d.Net),
+			"autoconnect":    dbus.MakeVariant(false),
+		},
+		"ipv4": {"method": dbus.MakeVariant("auto")},
+		"ipv6": {"method": dbus.MakeVariant("auto")},
+	}
+	options := map[string]dbus.Variant{"persist": dbus.MakeVariant("volatile")}
+	var profile, active dbus.ObjectPath
+	var result map[string]dbus.Variant
+	err := nm.Call(iface+".AddAndActivateConnection2", 0, settings, device, dbus.ObjectPath("/"), options).
+		Store(&profile, &active, &result)
+	if err != nil {
+		return err
+	}
+	p.active[d.Imei] = connection{active: active, net: d.Net}
+	return nil
+}
+
+// deactivate ends the connection activated for a modem, if any.
+// p.mu must be held.
+func (p *plugin) deactivate(imei string) error {
+	c, ok := p.active[imei]
+	if !ok {
+		return nil
+	}
+	delete(p.active, imei)
+	return p.conn.Object(service, path).Call(iface+".DeactivateConnection", 0, c.active).Err
+}
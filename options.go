@@ -0,0 +1,106 @@
+package modem
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option configures a Manager created by New.
+type Option func(*Manager)
+
+// Wait d after a modem appears before probing it. Defaults to 5 seconds.
+func WithSettleDelay(d time.Duration) Option {
+	return func(m *Manager) {
+		m.settle, m.optSettle = d, d
+	}
+}
+
+// Probe up to n modems at once. Defaults to 1.
+func WithProbeConcurrency(n int) Option {
+	return func(m *Manager) {
+		if n < 1 {
+			n = 1
+		}
+		m.probeSlots = make(chan struct{}, n)
+	}
+}
+
+// Try the given baud rates, in order, when detecting the rate of a port.
+// Defaults to 115200, 9600 and 460800.
+func WithBaudRates(rates ...int) Option {
+	return func(m *Manager) {
+		if len(rates) > 0 {
+			m.baudRates = rates
+		}
+	}
+}
+
+// Capture serial traffic to files in dir, see SetCapture.
+func WithCapture(dir string) Option {
+	return func(m *Manager) {
+		if err := m.SetCapture(dir); err != nil {
+			m.log.Warn("capture not started", "dir", dir, "err", err)
+		}
+	}
+}
+
+// Log to l, see SetLogger.
+func WithLogger(l *slog.Logger) Option {
+	return func(m *Manager) {
+		m.SetLogger(l)
+	}
+}
+
+// Use c for every internal delay, see SetClock.
+func WithClock(c Clock) Option {
+	return func(m *Manager) {
+		m.SetClock(c)
+	}
+}
+
+// Install instrumentation hooks, see SetHooks.
+func WithHooks(h Hooks) Option {
+	return func(m *Manager) {
+		m.SetHooks(h)
+	}
+}
+
+// Install fault injection hooks, see SetFaults.
+func WithFaults(f Faults) Option {
+	return func(m *Manager) {
+		m.SetFaults(f)
+	}
+}
+
+// Poll ready modems for unread text messages every d and publish them as
+// EventSMS. The modem marks them as read, they stay stored on it unless
+// WithSMSDelete is given. Off by default.
+func WithSMSPoll(d time.Duration) Option {
+	return func(m *Manager) {
+		m.smsPoll, m.optSMSPoll = d, d
+	}
+}
+
+// Delete polled messages from the modem once their EventSMS was received
+// by every Events subscriber, or by the OnEvent hook if there is none.
+// A message a subscriber missed, see WithEventBuffer, is kept on the
+// modem, marked as read. Off by default.
+func WithSMSDelete() Option {
+	return func(m *Manager) {
+		m.smsDelete, m.optSMSDelete = true, true
+	}
+}
+
+// Run the plugins alongside the monitor.
+func WithPlugins(p ...Plugin) Option {
+	return func(m *Manager) {
+		m.plugins = append(m.plugins, p...)
+	}
+}
+
+// Discover devices through b instead of udev.
+func WithBackend(b Backend) Option {
+	return func(m *Manager) {
+		m.backend = b
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Package otelmodem traces the AT commands and probes of a modem.Manager
+with OpenTelemetry.
+
+	m := modem.New(modem.WithHooks(otelmodem.Hooks(nil)))
+
+Every command becomes a span named after the command verb (AT+CMGS,
+AT+CSQ, ...) with the port, IMEI, command and result code as attributes.
+The manager redacts the PIN and password arguments of AT+CPIN, AT+CLCK
+and AT+CPWD before they reach the span.
+Spans are children of the context passed to SendATContext and friends,
+so a request traced by otelhttp or otelgrpc is followed down to the
+modem. Without an SDK installed the global tracer provider is a no-op.
+*/
+package otelmodem
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scope = "github.com/ausrasul/modem/otelmodem"
+
+// Attribute keys set on spans.
+const (
+	PortKey   = attribute.Key("modem.port")
+	ImeiKey   = attribute.Key("modem.imei")
+	CmdKey    = attribute.Key("modem.at.command")
+	ResultKey = attribute.Key("modem.at.result")
+)
+
+// Hooks returns manager hooks recording spans with tp, or with the global
+// tracer provider if tp is nil.
+func Hooks(tp trace.TracerProvider) modem.Hooks {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	tracer := tp.Tracer(scope)
+	return modem.Hooks{
+		OnCommandStart: func(ctx context.Context, c modem.CommandInfo) func(error) {
+			_, span := tracer.Start(ctx, spanName(c.Cmd),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(PortKey.String(c.Port), ImeiKey.String(c.Imei), CmdKey.String(c.Cmd)),
+			)
+			return func(err error) {
+				span.SetAttributes(ResultKey.String(result(err)))
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				}
+				span.End()
+			}
+		},
+		OnProbeEnd: func(port string, imei string, err error, elapsed time.Duration) {
+			end := time.Now()
+			_, span := tracer.Start(context.Background(), "modem.probe",
+				trace.WithTimestamp(end.Add(-elapsed)),
+				trace.WithAttributes(PortKey.String(port), ImeiKey.String(imei)),
+			)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End(trace.WithTimestamp(end))
+		},
+	}
+}
+
+// spanName is the command without its arguments, keeping span names
+// low in cardinality: AT+CMGS="+4670..." becomes AT+CMGS.
+func spanName(cmd string) string {
+	if i := strings.IndexAny(cmd, "=?"); i > 0 {
+		return cmd[:i]
+	}
+	return cmd
+}
+
+// result is the final result code a command ended with.
+func result(err error) string {
+	var cmdErr *modem.CommandError
+	switch {
+	case err == nil:
+		return "OK"
+	case errors.As(err, &cmdErr):
+		return cmdErr.Result
+	case errors.Is(err, modem.ErrTimeout):
+		return "TIMEOUT"
+	}
+	return "IO ERROR"
+}
@@ -0,0 +1,116 @@
+package modem
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DeviceConfig overrides the manager-wide settings for some modems, see
+// Config.Devices. Zero fields keep the manager-wide setting.
+type DeviceConfig struct {
+	SettleDelay  Duration `json:"settle_delay" yaml:"settle_delay"`
+	Baud         int      `json:"baud" yaml:"baud"` // skips baud rate detection
+	InitCommands []string `json:"init_commands" yaml:"init_commands"`
+	// bNumEndpoints of the USB interface of the command port, "03" by default.
+	CommandEndpoints string `json:"command_endpoints" yaml:"command_endpoints"`
+	// bInterfaceNumber of the command port, e.g. "02". Takes precedence
+	// over CommandEndpoints and the built-in tables of known modems, and
+	// is needed for modems with several ports of the same endpoint count.
+	CommandInterface string `json:"command_interface" yaml:"command_interface"`
+}
+
+var (
+	deviceKey = regexp.MustCompile(`^[0-9a-fA-F]{4}:[0-9a-fA-F]{4}(:.+)?$`)
+	ifaceRe   = regexp.MustCompile(`^[0-9a-fA-F]{2}$`)
+)
+
+func (c DeviceConfig) validate(key string) error {
+	var errs []error
+	if !deviceKey.MatchString(key) {
+		errs = append(errs, fmt.Errorf("devices: %q is not vid:pid or vid:pid:serial", key))
+	}
+	if c.SettleDelay < 0 {
+		errs = append(errs, fmt.Errorf("devices[%s]: settle_delay must not be negative", key))
+	}
+	if c.CommandInterface != "" && !ifaceRe.MatchString(c.CommandInterface) {
+		errs = append(errs, fmt.Errorf("devices[%s]: command_interface must be 2 hex digits", key))
+	}
+	if _, ok := baudFlags[c.Baud]; c.Baud != 0 && !ok {
+		errs = append(errs, fmt.Errorf("devices[%s]: unsupported baud rate %d", key, c.Baud))
+	}
+	for i, cmd := range c.InitCommands {
+		if !atPrefix.MatchString(cmd) || strings.ContainsAny(cmd, "\r\n\x1a") {
+			errs = append(errs, fmt.Errorf("devices[%s]: init_commands[%d]: %q is not a single AT command", key, i, cmd))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Override the manager-wide settings for the modems matching key, which
+// is "vid:pid" or "vid:pid:serial" with the USB serial number. A serial
+// number match wins over a vid:pid one. Applies to modems plugged in
+// afterwards. Overrides set this way are kept by Reload, and win over the
+// configuration's for the same key.
+func (m *Manager) SetDeviceConfig(key string, c DeviceConfig) error {
+	if err := c.validate(key); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.setDevices == nil {
+		m.setDevices = make(map[string]DeviceConfig)
+	}
+	m.setDevices[strings.ToLower(key)] = c
+	devices := make(map[string]DeviceConfig, len(m.cfg.Devices)+1)
+	for k, v := range m.cfg.Devices {
+		devices[k] = v
+	}
+	devices[strings.ToLower(key)] = c
+	m.cfg.Devices = devices
+	return nil
+}
+
+// deviceConfig finds the overrides of a USB device. The serial number is
+// only read when some override needs it.
+func (m *Manager) deviceConfig(vid, pid string, usbDev Device) (DeviceConfig, bool) {
+	m.mu.Lock()
+	devices := m.cfg.Devices
+	m.mu.Unlock()
+	if len(devices) == 0 {
+		return DeviceConfig{}, false
+	}
+	key := strings.ToLower(vid + ":" + pid)
+	for k := range devices {
+		if strings.HasPrefix(k, key+":") {
+			if c, ok := devices[key+":"+strings.ToLower(usbDev.Attr("serial"))]; ok {
+				return c, true
+			}
+			break
+		}
+	}
+	c, ok := devices[key]
+	return c, ok
+}
+
+// settleFor returns the settle delay of a port.
+func (m *Manager) settleFor(node string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.overrides[node]; ok && c.SettleDelay > 0 {
+		return time.Duration(c.SettleDelay)
+	}
+	return m.settle
+}
+
+// initFor returns the init commands of a port.
+func (m *Manager) initFor(node string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.overrides[node]; ok && c.InitCommands != nil {
+		return c.InitCommands
+	}
+	return m.cfg.InitCommands
+}
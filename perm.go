@@ -0,0 +1,55 @@
+package modem
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"syscall"
+)
+
+// PermissionError is returned when this process may not open a modem port.
+type PermissionError struct {
+	Port  string
+	Group string // group owning the port, e.g. dialout
+	Mode  os.FileMode
+	Hint  string // what to change to get access
+	Err   error
+}
+
+func (e *PermissionError) Error() string {
+	if e.Group == "" {
+		return e.Port + ": permission denied"
+	}
+	return fmt.Sprintf("%s: permission denied (group %s, mode %s), %s", e.Port, e.Group, e.Mode, e.Hint)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// permissionError describes why port can't be opened.
+func permissionError(port string, err error) error {
+	e := &PermissionError{Port: port, Err: err}
+	fi, serr := os.Stat(port)
+	if serr != nil {
+		return e
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return e
+	}
+	e.Mode = fi.Mode().Perm()
+	e.Group = strconv.FormatUint(uint64(st.Gid), 10)
+	if g, err := user.LookupGroupId(e.Group); err == nil {
+		e.Group = g.Name
+	}
+	groups, _ := os.Getgroups()
+	if slices.Contains(groups, int(st.Gid)) || os.Getegid() == int(st.Gid) {
+		e.Hint = "the group may not read and write it, check the udev rules setting its mode"
+	} else {
+		e.Hint = fmt.Sprintf("add the user to group %s (usermod -aG %s $USER) and log in again", e.Group, e.Group)
+	}
+	return e
+}
@@ -0,0 +1,9 @@
+package modem
+
+// Plugin extends a Manager, typically by forwarding its events somewhere.
+// Plugins passed to WithPlugins are started by Monitor, in order, and
+// stopped by StopMonitor.
+type Plugin interface {
+	Start(m *Manager) error
+	Stop() error
+}
@@ -0,0 +1,156 @@
+package modem
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CommandPolicy limits the AT commands callers can have the manager send,
+// for deployments exposing it through an API. Entries are command
+// prefixes, such as "AT+CFUN" or "AT&F", matched without regard to case
+// and spaces. Commands chained with ";", or basic commands written one
+// after the other as in ATE0&F, are checked one by one.
+//
+// The policy applies to the commands of SendAT and to those SendSMS
+// (AT+CMGF, AT+CMGS), Connect (AT+CGDCONT, AT+CGACT) and Disconnect
+// (AT+CGACT) send for the caller, whatever the backend. The commands the
+// manager sends on its own, for the probe, setup, init commands, tasks,
+// SMS polling and queries such as Signal and Registration, are not
+// checked, so plugins using those keep working with an allowlist.
+type CommandPolicy struct {
+	// Only commands starting with one of these are sent. Empty allows all.
+	Allow []string `json:"allow" yaml:"allow"`
+	// Commands starting with one of these are refused, even if allowed.
+	Deny []string `json:"deny" yaml:"deny"`
+}
+
+// ErrUnsafeCommand is returned by SendAT for commands that are not a single
+// line starting with AT.
+var ErrUnsafeCommand = errors.New("Not a single AT command")
+
+// ErrCommandDenied is wrapped by the errors SendAT, SendSMS, Connect and
+// Disconnect return for commands the CommandPolicy refuses.
+var ErrCommandDenied = errors.New("AT command not allowed")
+
+func (p CommandPolicy) validate() error {
+	var errs []error
+	for i, a := range p.Allow {
+		if !atPrefix.MatchString(a) {
+			errs = append(errs, fmt.Errorf("commands.allow[%d]: %q does not start with AT", i, a))
+		}
+	}
+	for i, d := range p.Deny {
+		if !atPrefix.MatchString(d) {
+			errs = append(errs, fmt.Errorf("commands.deny[%d]: %q does not start with AT", i, d))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allowed checks the commands sent for a caller against the policy.
+func (m *Manager) allowed(cmds ...string) error {
+	m.mu.Lock()
+	policy := m.cfg.Commands
+	m.mu.Unlock()
+	for _, cmd := range cmds {
+		if err := policy.check(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check reports why cmd may not be sent, if it may not.
+func (p CommandPolicy) check(cmd string) error {
+	if !atPrefix.MatchString(cmd) {
+		return ErrUnsafeCommand
+	}
+	for _, r := range cmd {
+		// CR, LF and Ctrl-Z would end the command or start another one.
+		if r < 0x20 || r == 0x7f {
+			return ErrUnsafeCommand
+		}
+	}
+	for _, c := range chained(cmd) {
+		if matchPrefix(p.Deny, c) || len(p.Allow) > 0 && !matchPrefix(p.Allow, c) {
+			return fmt.Errorf("%s: %w", c, ErrCommandDenied)
+		}
+	}
+	return nil
+}
+
+// chained splits a command line into its commands, upper-cased and without
+// spaces outside quotes, each with the AT prefix: "at+csq; +cops?" is
+// AT+CSQ and AT+COPS?, and ATE0&F is ATE0 and AT&F.
+func chained(cmd string) []string {
+	var cmds []string
+	var b strings.Builder
+	quoted := false
+	for _, r := range cmd {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case quoted:
+			b.WriteRune(r)
+		case r == ' ':
+		case r == ';':
+			cmds = append(cmds, b.String())
+			b.Reset()
+		default:
+			b.WriteString(strings.ToUpper(string(r)))
+		}
+	}
+	cmds = append(cmds, b.String())
+	var out []string
+	for i, c := range cmds {
+		if i == 0 || strings.HasPrefix(c, "AT") {
+			c = c[2:]
+		}
+		out = append(out, basic(c)...)
+	}
+	return out
+}
+
+// basic splits the basic commands a command line may chain without
+// separator, as in ATE0&F, and returns them with the AT prefix. An
+// extended or dial command runs to the end of the line.
+func basic(line string) []string {
+	var cmds []string
+	for line != "" {
+		n := 1
+		switch line[0] {
+		case '+', '#', '$', '^', '%', '*', 'D':
+			n = len(line)
+		case '&':
+			n = min(2, len(line))
+		}
+		n = skipDigits(line, n)
+		if n < len(line) && (line[n] == '=' || line[n] == '?') {
+			n = skipDigits(line, n+1)
+		}
+		cmds = append(cmds, "AT"+line[:n])
+		line = line[n:]
+	}
+	if cmds == nil {
+		cmds = []string{"AT"}
+	}
+	return cmds
+}
+
+func skipDigits(s string, i int) int {
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+func matchPrefix(prefixes []string, cmd string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(cmd, strings.ToUpper(strings.ReplaceAll(p, " ", ""))) {
+			return true
+		}
+	}
+	return false
+}
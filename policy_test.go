@@ -0,0 +1,84 @@
+package modem
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestChained(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{"AT", []string{"AT"}},
+		{"AT+CSQ", []string{"AT+CSQ"}},
+		{"at+csq; +cops?", []string{"AT+CSQ", "AT+COPS?"}},
+		{"AT+CSQ;AT+CFUN=0", []string{"AT+CSQ", "AT+CFUN=0"}},
+		{"ATE0&F", []string{"ATE0", "AT&F"}},
+		{"ATE0V1", []string{"ATE0", "ATV1"}},
+		{"AT&F0E1", []string{"AT&F0", "ATE1"}},
+		{"ATS0=1", []string{"ATS0=1"}},
+		{"ATS7?", []string{"ATS7?"}},
+		{"ATD*99#", []string{"ATD*99#"}},
+		{`AT+CMGS="+46 70;1"`, []string{`AT+CMGS="+46 70;1"`}},
+		{`AT+COPS=1,0,"abc"`, []string{`AT+COPS=1,0,"abc"`}},
+	}
+	for _, tt := range tests {
+		if got := chained(tt.cmd); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chained(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestCommandPolicyCheck(t *testing.T) {
+	deny := CommandPolicy{Deny: []string{"AT+CFUN", "AT&F", "ATZ"}}
+	allow := CommandPolicy{Allow: []string{"AT+CSQ", "AT+COPS?", "at + creg"}}
+	tests := []struct {
+		policy CommandPolicy
+		cmd    string
+		want   error
+	}{
+		{CommandPolicy{}, "AT+CFUN=0", nil},
+		{CommandPolicy{}, "AT+CSQ\r", ErrUnsafeCommand},
+		{CommandPolicy{}, "AT+CSQ\nAT+CFUN=0", ErrUnsafeCommand},
+		{CommandPolicy{}, "AT+CMGS=\"1\"\x1a", ErrUnsafeCommand},
+		{CommandPolicy{}, "+CSQ", ErrUnsafeCommand},
+		{CommandPolicy{}, "", ErrUnsafeCommand},
+		{deny, "AT+CSQ", nil},
+		{deny, "AT+CFUN=0", ErrCommandDenied},
+		{deny, "at+cfun=1,1", ErrCommandDenied},
+		{deny, "AT + CFUN = 0", ErrCommandDenied},
+		{deny, "AT+CSQ;+CFUN=0", ErrCommandDenied},
+		{deny, "ATE0&F", ErrCommandDenied},
+		{deny, "ATE0Z", ErrCommandDenied},
+		{deny, `AT+CMGS="AT+CFUN"`, nil},
+		{allow, "AT+CSQ", nil},
+		{allow, "AT+COPS?", nil},
+		{allow, "AT+CREG?", nil},
+		{allow, "AT+COPS=2", ErrCommandDenied},
+		{allow, "AT+CSQ;+CFUN=0", ErrCommandDenied},
+		{allow, "AT", ErrCommandDenied},
+		{CommandPolicy{Allow: []string{"AT+CFUN"}, Deny: []string{"AT+CFUN=0"}}, "AT+CFUN=0", ErrCommandDenied},
+	}
+	for _, tt := range tests {
+		err := tt.policy.check(tt.cmd)
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%+v check(%q) = %v, want %v", tt.policy, tt.cmd, err, tt.want)
+		}
+	}
+}
+
+func TestPolicyCoversBuiltCommands(t *testing.T) {
+	m := New()
+	m.cfg.Commands = CommandPolicy{Deny: []string{"AT+CMGS", "AT+CGDCONT", "AT+CGACT"}}
+	if err := m.SendSMS("490154203237518", "+4670", "hi"); !errors.Is(err, ErrCommandDenied) {
+		t.Errorf("SendSMS = %v, want ErrCommandDenied", err)
+	}
+	if err := m.Disconnect("490154203237518"); !errors.Is(err, ErrCommandDenied) {
+		t.Errorf("Disconnect = %v, want ErrCommandDenied", err)
+	}
+	if _, err := m.Registration("490154203237518"); !errors.Is(err, ErrNoModem) {
+		t.Errorf("Registration = %v, want ErrNoModem", err)
+	}
+}
@@ -0,0 +1,151 @@
+package modem
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// PortRole selects one of a modem's ports.
+type PortRole int
+
+const (
+	PortCommand PortRole = iota // the AT command port, Modem.Tty
+	PortData                    // a second AT port for PPP, it answers AT too
+	PortNMEA                    // GNSS sentences
+	PortDiag                    // vendor diagnostics, e.g. Qualcomm DM
+	PortMBIM                    // MBIM control channel, a cdc-wdm node
+	PortQMI                     // QMI control channel, a cdc-wdm node
+)
+
+func (r PortRole) String() string {
+	switch r {
+	case PortCommand:
+		return "command"
+	case PortData:
+		return "data"
+	case PortNMEA:
+		return "nmea"
+	case PortDiag:
+		return "diag"
+	case PortMBIM:
+		return "mbim"
+	case PortQMI:
+		return "qmi"
+	}
+	return "unknown"
+}
+
+// Port is one tty or control channel of a modem.
+type Port struct {
+	Node      string // e.g. /dev/ttyUSB2 or /dev/cdc-wdm0
+	Role      PortRole
+	Interface string // USB interface number, e.g. 03
+}
+
+var ErrPortHeld = errors.New("Port is held open by OpenPort")
+
+// Open the modem's port with the given role for exclusive use by the
+// caller, e.g. to run pppd or a custom protocol on it, or an MBIM or QMI
+// client on its control channel. It waits for a
+// command the manager is running on the port to finish. Until the port is
+// closed the manager sends nothing on it: its own SMS polls and probes
+// skip the modem, and SendAT, SendSMS and the like return ErrPortHeld.
+func (d Modem) OpenPort(role PortRole) (io.ReadWriteCloser, error) {
+	m := d.m
+	if m == nil {
+		return nil, ErrNoModem
+	}
+	if _, ok := m.backend.(PortOwner); ok {
+		return nil, errors.New("Ports are owned by the backend")
+	}
+	cur, err := m.modemByImei(d.Imei)
+	if err != nil {
+		return nil, err
+	}
+	node := cur.port(role)
+	if node == "" {
+		return nil, errors.New("Modem has no " + role.String() + " port")
+	}
+	l := m.portLock(node)
+	l.Lock()
+	m.mu.Lock()
+	m.held[node] = true
+	m.mu.Unlock()
+	release := func() {
+		m.mu.Lock()
+		delete(m.held, node)
+		m.mu.Unlock()
+		l.Unlock()
+	}
+	var rw io.ReadWriteCloser
+	if role == PortMBIM || role == PortQMI {
+		// Control channels are no ttys, their messages pass unchanged.
+		rw, err = os.OpenFile(node, os.O_RDWR, 0)
+	} else {
+		rw, err = m.openPort(node)
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &heldPort{ReadWriteCloser: rw, release: release}, nil
+}
+
+// port returns the device node of the port with the given role.
+func (d Modem) port(role PortRole) string {
+	if role == PortCommand {
+		return d.Tty
+	}
+	for _, p := range d.Ports {
+		if p.Role == role {
+			return p.Node
+		}
+	}
+	return ""
+}
+
+// setPort records the role of a port of the modem stored under key. A
+// ready modem whose ports changed gets an update event, the ports of a
+// modem being probed come with its add event.
+// Ports is replaced rather than modified, copies handed out keep theirs.
+func (m *Manager) setPort(key string, p Port) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.devices[key]
+	if !ok || slices.Contains(d.Ports, p) {
+		return
+	}
+	ports := make([]Port, 0, len(d.Ports)+1)
+	for _, old := range d.Ports {
+		if old.Node != p.Node {
+			ports = append(ports, old)
+		}
+	}
+	d.Ports = append(ports, p)
+	m.devices[key] = d
+	if d.State == StateReady {
+		m.publishLocked("update", d)
+	}
+}
+
+// isHeld reports whether a port is held open by OpenPort.
+func (m *Manager) isHeld(node string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.held[node]
+}
+
+type heldPort struct {
+	io.ReadWriteCloser
+	once    sync.Once
+	release func()
+}
+
+func (p *heldPort) Close() error {
+	err := p.ReadWriteCloser.Close()
+	p.once.Do(p.release)
+	return err
+}
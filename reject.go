@@ -0,0 +1,49 @@
+package modem
+
+// Reasons a device is not adopted.
+const (
+	RejectSubsystem = "subsystem" // neither a tty, a network interface nor an MBIM or QMI channel
+	RejectNotUSB    = "not usb"   // no USB device among its parents
+	RejectFilter    = "filter"    // vendor/product id matches no filter
+	RejectEndpoints = "endpoints" // tty is not the AT command port
+	RejectProbe     = "probe"     // the port did not answer the IMEI query
+)
+
+// Device seen by the monitor but not adopted as a modem.
+type RejectedDevice struct {
+	Name      string // kernel name, e.g. ttyUSB1
+	Node      string // device node, empty for network interfaces
+	Subsystem string
+	Vid       string
+	Pid       string
+	Reason    string
+	Err       error // probe error for RejectProbe
+}
+
+// Set a handler called for every device event of a modem, with the
+// action and the modem as far as it is known then, before it is probed.
+// dev is the tty or network interface of the event, its attributes and
+// Property can be read during the call only. Meant for information Modem
+// doesn't carry, e.g. Property(dev, "ID_MODEL"); handlers set with
+// AddHandler get the modem once it is ready.
+func (m *Manager) SetRawHandler(raw func(action string, d Modem, dev Device)) {
+	m.handleRaw = raw
+}
+
+// Set a handler called for every device seen but not adopted, with the
+// reason. Useful to find out why a modem never shows up.
+func (m *Manager) SetRejectHandler(reject func(RejectedDevice)) {
+	m.handleReject = reject
+}
+
+// reject reports dev to the reject handler, if there is one. The device
+// attributes are only read when someone is listening.
+func (m *Manager) reject(dev Device, r RejectedDevice) {
+	if m.handleReject == nil {
+		return
+	}
+	r.Name = dev.SysName()
+	r.Node = dev.DevNode()
+	r.Subsystem = dev.Subsystem()
+	m.handleReject(r)
+}
@@ -0,0 +1,40 @@
+package modem
+
+// Apply a new configuration to the manager while it runs, e.g. after its
+// file changed. Nothing is applied if c is invalid. Adopted modems are
+// kept, even those no filter matches anymore, and get an update event if
+// their alias changed. Filters match from the next device event; PINs,
+// APNs, device overrides, init commands and the serial settings apply to
+// the modems probed and the commands sent from then on. Exec hooks, tasks
+// and the SMS poll interval take effect at the next Monitor. Filters
+// added with AddFilter and overrides set with SetDeviceConfig stay;
+// aliases set with SetAlias are replaced by those of c, except the ones
+// kept by a Store. Unlike with WithConfig, the settle delay, SMS poll
+// interval and SMS deletion left zero in c go back to the values the
+// options of New gave them, or to their defaults.
+func (m *Manager) Reload(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	for _, f := range m.cfg.Filters {
+		k := filter{vid: f.Vid, pid: f.Pid}
+		if !m.added[k] {
+			delete(m.filters, k)
+		}
+	}
+	m.settle, m.smsPoll, m.smsDelete = m.optSettle, m.optSMSPoll, m.optSMSDelete
+	m.applyLocked(c)
+	if m.known != nil {
+		m.restoreAliasesLocked()
+	}
+	for _, d := range m.relabelLocked() {
+		m.publishLocked("update", d)
+	}
+	m.mu.Unlock()
+	for _, t := range c.Tasks {
+		m.SetTaskEnabled(t.Name, !t.Disabled)
+	}
+	m.log.Info("configuration reloaded", "filters", len(c.Filters), "devices", len(c.Devices))
+	return nil
+}
@@ -0,0 +1,60 @@
+package modem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReloadKeepsDeviceConfig(t *testing.T) {
+	m := New(WithConfig(&Config{Devices: map[string]DeviceConfig{"12d1:1003": {Baud: 9600}}}))
+	if err := m.SetDeviceConfig("12D1:1001", DeviceConfig{Baud: 9600}); err != nil {
+		t.Fatal(err)
+	}
+	err := m.Reload(&Config{Devices: map[string]DeviceConfig{
+		"12d1:1001": {Baud: 115200},
+		"12d1:1002": {Baud: 115200},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"12d1:1001": 9600, "12d1:1002": 115200}
+	if len(m.cfg.Devices) != len(want) {
+		t.Errorf("devices %v, want %v", m.cfg.Devices, want)
+	}
+	for key, baud := range want {
+		if got := m.cfg.Devices[key].Baud; got != baud {
+			t.Errorf("%s: baud %d, want %d", key, got, baud)
+		}
+	}
+}
+
+func TestReloadSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		reload *Config // nil for none
+		settle time.Duration
+		poll   time.Duration
+		delete bool
+	}{
+		{"defaults", nil, nil, defaultSettle, 0, false},
+		{"zero config keeps options", []Option{WithSettleDelay(time.Second), WithSMSPoll(time.Minute), WithConfig(&Config{})}, nil, time.Second, time.Minute, false},
+		{"config", []Option{WithConfig(&Config{SettleDelay: Duration(time.Second), SMSPoll: Duration(time.Minute), SMSDelete: true})}, nil, time.Second, time.Minute, true},
+		{"reload applies zero", []Option{WithConfig(&Config{SettleDelay: Duration(time.Second), SMSPoll: Duration(time.Minute), SMSDelete: true})}, &Config{}, defaultSettle, 0, false},
+		{"reload keeps options", []Option{WithSettleDelay(time.Second), WithSMSPoll(time.Minute), WithSMSDelete()}, &Config{}, time.Second, time.Minute, true},
+		{"reload drops config over options", []Option{WithSettleDelay(time.Second), WithConfig(&Config{SettleDelay: Duration(time.Hour), SMSPoll: Duration(time.Hour)})}, &Config{}, time.Second, 0, false},
+		{"reload sets", nil, &Config{SettleDelay: Duration(2 * time.Second), SMSPoll: Duration(time.Hour), SMSDelete: true}, 2 * time.Second, time.Hour, true},
+	}
+	for _, tt := range tests {
+		m := New(tt.opts...)
+		if tt.reload != nil {
+			if err := m.Reload(tt.reload); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if m.settle != tt.settle || m.smsPoll != tt.poll || m.smsDelete != tt.delete {
+			t.Errorf("%s: settle %v, sms poll %v, sms delete %v, want %v, %v, %v",
+				tt.name, m.settle, m.smsPoll, m.smsDelete, tt.settle, tt.poll, tt.delete)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package modem
+
+import "time"
+
+// A ready modem that was unplugged, kept in case it comes back.
+type departure struct {
+	d   Modem
+	gen uint64
+}
+
+// Treat a modem that comes back with the same IMEI within d of being
+// removed, e.g. after a firmware reset or on another USB port, as the same
+// modem: it gets an update event with its new ports instead of a remove
+// and an add, keeps its Since and last jamming state, and its tasks keep
+// their schedule. Remove events are delayed by d. Defaults to zero, which
+// removes modems at once.
+func WithReplugGrace(d time.Duration) Option {
+	return func(m *Manager) {
+		m.replugGrace = d
+	}
+}
+
+// depart handles the removal of a modem. Ready modems are only published
+// as removed once the replug grace period passed without them coming back.
+func (m *Manager) depart(stop chan struct{}, usb string, d Modem) {
+	if m.replugGrace <= 0 || d.State != StateReady {
+		m.log.Info("modem removed", "usb", usb, "imei", d.Imei)
+		m.publish("remove", d)
+		return
+	}
+	m.mu.Lock()
+	m.departGen++
+	gen := m.departGen
+	m.departed[d.Imei] = departure{d: d, gen: gen}
+	m.mu.Unlock()
+	m.log.Debug("modem unplugged, waiting for it to come back", "usb", usb, "imei", d.Imei)
+
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		select {
+		case <-m.clock.After(m.replugGrace):
+		case <-stop:
+			return
+		}
+		m.mu.Lock()
+		dep, ok := m.departed[d.Imei]
+		ok = ok && dep.gen == gen
+		if ok {
+			delete(m.departed, d.Imei)
+		}
+		m.mu.Unlock()
+		if ok {
+			m.log.Info("modem removed", "usb", usb, "imei", d.Imei)
+			m.publish("remove", d)
+		}
+	}()
+}
+
+// arriveLocked prepares a modem that just became ready and returns the
+// action to publish it with: update if it is a departed modem coming
+// back. m.mu must be held.
+func (m *Manager) arriveLocked(d *Modem, action string) string {
+	if dep, ok := m.departed[d.Imei]; ok {
+		delete(m.departed, d.Imei)
+		d.Since = dep.d.Since
+		if d.Jamming == JammingUnknown {
+			d.Jamming = dep.d.Jamming
+		}
+		m.log.Info("modem came back", "imei", d.Imei, "tty", d.Tty)
+		return "update"
+	}
+	if d.Since.IsZero() {
+		d.Since = m.clock.Now()
+	}
+	return action
+}
+
+// departing reports whether the modem with the given IMEI is unplugged
+// and may still come back.
+func (m *Manager) departing(imei string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.departed[imei]
+	return ok
+}
@@ -0,0 +1,193 @@
+package modem
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// chanBackend reports the devices sent on it until the monitor stops.
+type chanBackend chan Device
+
+func (b chanBackend) Run(stop <-chan struct{}, handle func(Device)) error {
+	for {
+		select {
+		case dev := <-b:
+			handle(dev)
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// tickClock fires the timers of each duration when the test sends on
+// tick(d). Every timer of the same duration shares one channel.
+type tickClock struct {
+	mu    sync.Mutex
+	ticks map[time.Duration]chan time.Time
+}
+
+func (c *tickClock) Now() time.Time        { return time.Now() }
+func (c *tickClock) Sleep(d time.Duration) { <-c.After(d) }
+
+func (c *tickClock) After(d time.Duration) <-chan time.Time {
+	return c.tick(d)
+}
+
+func (c *tickClock) tick(d time.Duration) chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ticks == nil {
+		c.ticks = make(map[time.Duration]chan time.Time)
+	}
+	if c.ticks[d] == nil {
+		c.ticks[d] = make(chan time.Time)
+	}
+	return c.ticks[d]
+}
+
+// replugMonitor starts m on a chanBackend with an event subscription.
+func replugMonitor(t *testing.T, m *Manager) (chanBackend, <-chan ModemEvent) {
+	t.Helper()
+	devs := make(chanBackend)
+	m.backend = devs
+	m.AddFilter("12d1", "1001")
+	events := m.Events()
+	if err := m.Monitor(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.StopMonitor() })
+	return devs, events
+}
+
+// settle returns once the backend handled the devices sent before, by
+// sending it one it rejects.
+func (b chanBackend) settle() {
+	b <- &fakeDevice{action: "add", subsystem: "block"}
+}
+
+func next(t *testing.T, events <-chan ModemEvent) ModemEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+	return ModemEvent{}
+}
+
+const replugImei = "490154203237518"
+
+func TestReplugWithinGrace(t *testing.T) {
+	m := New(WithReplugGrace(time.Minute))
+	m.SetClock(&tickClock{})
+	devs, events := replugMonitor(t, m)
+	usb1, tty1 := usbModem("1", replugImei)
+	_, tty2 := usbModem("2", replugImei)
+
+	devs <- tty1
+	added := next(t, events)
+	m.jamming(replugImei, JammingDetected)
+	next(t, events)
+	devs <- unplug(usb1)
+	devs <- tty2
+	ev := next(t, events)
+
+	if ev.Type != EventUpdate {
+		t.Fatalf("replug emitted %s, want update", ev.Type)
+	}
+	if ev.Modem.Tty != "/dev/ttyUSB2" {
+		t.Errorf("Tty = %s, want /dev/ttyUSB2", ev.Modem.Tty)
+	}
+	if !ev.Modem.Since.Equal(added.Modem.Since) {
+		t.Errorf("Since = %v, want %v", ev.Modem.Since, added.Modem.Since)
+	}
+	if ev.Modem.Jamming != JammingDetected {
+		t.Errorf("Jamming = %s, want detected", ev.Modem.Jamming)
+	}
+	list := m.List()
+	if d, ok := list["/dev/bus/usb/001/002"]; len(list) != 1 || !ok || d.Tty != "/dev/ttyUSB2" {
+		t.Errorf("List() = %+v, want the modem on /dev/ttyUSB2", list)
+	}
+}
+
+func TestReplugAfterGrace(t *testing.T) {
+	clock := &tickClock{}
+	m := New(WithReplugGrace(time.Minute))
+	m.SetClock(clock)
+	devs, events := replugMonitor(t, m)
+	usb1, tty1 := usbModem("1", replugImei)
+	_, tty2 := usbModem("2", replugImei)
+
+	devs <- tty1
+	next(t, events)
+	devs <- unplug(usb1)
+	clock.tick(time.Minute) <- time.Now()
+	if ev := next(t, events); ev.Type != EventRemove || ev.Modem.Tty != "/dev/ttyUSB1" {
+		t.Errorf("grace expiry emitted %s of %s, want remove of /dev/ttyUSB1", ev.Type, ev.Modem.Tty)
+	}
+	devs <- tty2
+	if ev := next(t, events); ev.Type != EventAdd {
+		t.Errorf("late replug emitted %s, want add", ev.Type)
+	}
+}
+
+func TestReplugWithoutGrace(t *testing.T) {
+	m := New()
+	devs, events := replugMonitor(t, m)
+	usb1, tty1 := usbModem("1", replugImei)
+	_, tty2 := usbModem("2", replugImei)
+
+	devs <- tty1
+	devs <- unplug(usb1)
+	devs <- tty2
+	var got []EventType
+	for i := 0; i < 3; i++ {
+		got = append(got, next(t, events).Type)
+	}
+	if got[0] != EventAdd || got[1] != EventRemove || got[2] != EventAdd {
+		t.Errorf("events %v, want [add remove add]", got)
+	}
+}
+
+func TestReplugKeepsTasks(t *testing.T) {
+	clock := &tickClock{}
+	m := New(WithReplugGrace(time.Minute))
+	m.SetClock(clock)
+	ran := make(chan string, 1)
+	m.AddTask(Task{Name: "probe", Every: Duration(time.Hour)}, func(d Modem) error {
+		ran <- d.Tty
+		return nil
+	})
+	devs, events := replugMonitor(t, m)
+	usb1, tty1 := usbModem("1", replugImei)
+	_, tty2 := usbModem("2", replugImei)
+
+	fire := func() {
+		t.Helper()
+		select {
+		case clock.tick(time.Hour) <- time.Now():
+		case <-time.After(5 * time.Second):
+			t.Fatal("task runner stopped")
+		}
+	}
+	devs <- tty1
+	next(t, events)
+	fire()
+	if tty := <-ran; tty != "/dev/ttyUSB1" {
+		t.Errorf("task ran on %s, want /dev/ttyUSB1", tty)
+	}
+	devs <- unplug(usb1)
+	devs.settle()
+	// Due twice while the modem is unplugged: skipped, and the second tick
+	// is only taken once the runner is done with the first.
+	fire()
+	fire()
+	devs <- tty2
+	next(t, events)
+	fire()
+	if tty := <-ran; tty != "/dev/ttyUSB2" {
+		t.Errorf("task ran on %s, want /dev/ttyUSB2", tty)
+	}
+}
@@ -0,0 +1,166 @@
+package modem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Built-in task actions, see Task.
+const (
+	TaskKeepalive = "keepalive" // sends AT
+	TaskSignal    = "signal"    // sends AT+CSQ, the reply is passed to OnTask
+	TaskSMS       = "sms"       // publishes unread messages, as WithSMSPoll does
+)
+
+// Task is an action run periodically on every ready modem, see
+// Config.Tasks and AddTask. Each modem runs it Every plus a random delay of
+// up to Jitter, counted from when it became ready or last ran the task.
+type Task struct {
+	Name string `json:"name" yaml:"name"`
+	// A built-in action or an AT command, whose reply is passed to OnTask.
+	Action   string   `json:"action" yaml:"action"`
+	Every    Duration `json:"every" yaml:"every"`
+	Jitter   Duration `json:"jitter" yaml:"jitter"`
+	Disabled bool     `json:"disabled" yaml:"disabled"` // see SetTaskEnabled
+}
+
+// task is a Task with its action resolved.
+type task struct {
+	Task
+	run func(d Modem) (string, error)
+}
+
+func (t Task) validate(i int) error {
+	var errs []error
+	if t.Name == "" {
+		errs = append(errs, fmt.Errorf("tasks[%d]: name is empty", i))
+	}
+	switch t.Action {
+	case TaskKeepalive, TaskSignal, TaskSMS:
+	default:
+		if !atPrefix.MatchString(t.Action) {
+			errs = append(errs, fmt.Errorf("tasks[%d]: %q is neither a built-in action nor an AT command", i, t.Action))
+		}
+	}
+	if t.Every <= 0 {
+		errs = append(errs, fmt.Errorf("tasks[%d]: every must be positive", i))
+	}
+	if t.Jitter < 0 {
+		errs = append(errs, fmt.Errorf("tasks[%d]: jitter must not be negative", i))
+	}
+	return errors.Join(errs...)
+}
+
+// Call run periodically on every ready modem, as scheduled by t. t.Action
+// is ignored. Must be called before Monitor.
+func (m *Manager) AddTask(t Task, run func(d Modem) error) {
+	m.tasks = append(m.tasks, task{Task: t, run: func(d Modem) (string, error) { return "", run(d) }})
+	m.SetTaskEnabled(t.Name, !t.Disabled)
+}
+
+// Start or stop running the task with the given name, on every modem.
+func (m *Manager) SetTaskEnabled(name string, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.disabledTasks == nil {
+		m.disabledTasks = make(map[string]bool)
+	}
+	m.disabledTasks[name] = !enabled
+}
+
+func (m *Manager) taskEnabled(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.disabledTasks[name]
+}
+
+// action returns the function running a configured task.
+func (m *Manager) action(t Task) func(d Modem) (string, error) {
+	switch t.Action {
+	case TaskKeepalive:
+		return func(d Modem) (string, error) { return m.command(context.Background(), d.Imei, "AT") }
+	case TaskSignal:
+		return func(d Modem) (string, error) { return m.command(context.Background(), d.Imei, "AT+CSQ") }
+	case TaskSMS:
+		return func(d Modem) (string, error) { return "", m.sweepSMS(d) }
+	}
+	return func(d Modem) (string, error) { return m.command(context.Background(), d.Imei, t.Action) }
+}
+
+// runTasks keeps a runner of every task going for each ready modem until
+// stop closes.
+func (m *Manager) runTasks(stop chan struct{}, events <-chan ModemEvent, tasks []task) {
+	defer m.workers.Done()
+	defer m.Unsubscribe(events)
+	running := make(map[string]chan struct{}) // by IMEI
+	defer func() {
+		for _, quit := range running {
+			close(quit)
+		}
+	}()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-events:
+		}
+		// Events may be dropped, so compare with the ready modems every time.
+		// Unplugged modems that may come back keep their runners.
+		ready := make(map[string]bool)
+		for _, d := range m.List() {
+			ready[d.Imei] = true
+			if running[d.Imei] != nil {
+				continue
+			}
+			quit := make(chan struct{})
+			running[d.Imei] = quit
+			for _, t := range tasks {
+				m.workers.Add(1)
+				go m.runTask(quit, d.Imei, t)
+			}
+		}
+		for imei, quit := range running {
+			if !ready[imei] && !m.departing(imei) {
+				close(quit)
+				delete(running, imei)
+			}
+		}
+	}
+}
+
+// runTask runs t on the modem with the given IMEI until quit closes.
+func (m *Manager) runTask(quit chan struct{}, imei string, t task) {
+	defer m.workers.Done()
+	for {
+		wait := time.Duration(t.Every)
+		if t.Jitter > 0 {
+			wait += rand.N(time.Duration(t.Jitter))
+		}
+		select {
+		case <-quit:
+			return
+		case <-m.clock.After(wait):
+		}
+		if !m.taskEnabled(t.Name) {
+			continue
+		}
+		d, err := m.modemByImei(imei)
+		if err != nil && m.departing(imei) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if m.isHeld(d.Tty) {
+			continue
+		}
+		out, err := t.run(d)
+		if err != nil {
+			m.log.Warn("task failed", "task", t.Name, "imei", imei, "err", err)
+		}
+		m.hooks.task(t.Name, d, out, err)
+	}
+}
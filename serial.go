@@ -0,0 +1,161 @@
+package modem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Line holds the serial line settings of modem ports. The zero value is
+// 8N1 without flow control.
+type Line struct {
+	Parity      string `json:"parity" yaml:"parity"`             // none, even or odd
+	StopBits    int    `json:"stop_bits" yaml:"stop_bits"`       // 1 or 2
+	FlowControl string `json:"flow_control" yaml:"flow_control"` // none or rtscts
+}
+
+func (l Line) validate() error {
+	var errs []error
+	switch l.Parity {
+	case "", "none", "even", "odd":
+	default:
+		errs = append(errs, fmt.Errorf("serial: parity must be none, even or odd, not %q", l.Parity))
+	}
+	switch l.StopBits {
+	case 0, 1, 2:
+	default:
+		errs = append(errs, fmt.Errorf("serial: stop_bits must be 1 or 2, not %d", l.StopBits))
+	}
+	switch l.FlowControl {
+	case "", "none", "rtscts":
+	default:
+		errs = append(errs, fmt.Errorf("serial: flow_control must be none or rtscts, not %q", l.FlowControl))
+	}
+	return errors.Join(errs...)
+}
+
+var baudFlags = map[int]uint32{
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	921600: unix.B921600,
+}
+
+// openSerial opens a modem port in raw mode with the given line settings.
+// The port is locked against other programs for as long as it is open,
+// with its UUCP lock file and in exclusive mode.
+func openSerial(port string, baud int, line Line) (io.ReadWriteCloser, error) {
+	speed, ok := baudFlags[baud]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported baud rate %d", baud)
+	}
+	unlock, err := lockPort(port)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(port, os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if errors.Is(err, unix.EBUSY) {
+		err = &PortBusyError{Port: port, PID: holder(port)}
+	}
+	if errors.Is(err, unix.EACCES) {
+		err = permissionError(port, err)
+	}
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	rc, err := f.SyscallConn()
+	if err != nil {
+		f.Close()
+		unlock()
+		return nil, err
+	}
+	var terr error
+	err = rc.Control(func(fd uintptr) {
+		terr = configure(int(fd), speed, line)
+		if terr == nil {
+			terr = unix.IoctlSetInt(int(fd), unix.TIOCEXCL, 0)
+		}
+	})
+	if err == nil {
+		err = terr
+	}
+	if err != nil {
+		f.Close()
+		unlock()
+		return nil, fmt.Errorf("%s: %w", port, err)
+	}
+	return &serialPort{f: f, unlock: unlock}, nil
+}
+
+// configure puts the terminal into raw mode at speed with the line settings.
+func configure(fd int, speed uint32, line Line) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL |
+		unix.IXON | unix.IXOFF | unix.IXANY | unix.INPCK
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.PARODD | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+	t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | speed
+	switch line.Parity {
+	case "even":
+		t.Cflag |= unix.PARENB
+		t.Iflag |= unix.INPCK
+	case "odd":
+		t.Cflag |= unix.PARENB | unix.PARODD
+		t.Iflag |= unix.INPCK
+	}
+	if line.StopBits == 2 {
+		t.Cflag |= unix.CSTOPB
+	}
+	if line.FlowControl == "rtscts" {
+		t.Cflag |= unix.CRTSCTS
+	}
+	t.Ispeed = speed
+	t.Ospeed = speed
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS, t)
+}
+
+// serialPort is an open modem port. Reads block until data arrives or
+// the read deadline passes.
+type serialPort struct {
+	f      *os.File
+	unlock func()
+}
+
+func (p *serialPort) Read(b []byte) (int, error) {
+	return p.f.Read(b)
+}
+
+func (p *serialPort) SetReadDeadline(t time.Time) error {
+	return p.f.SetReadDeadline(t)
+}
+
+func (p *serialPort) Write(b []byte) (int, error) {
+	return p.f.Write(b)
+}
+
+func (p *serialPort) Close() error {
+	// Exclusive mode lasts until the tty's last handle is closed, which is
+	// not this one while another process, such as modemtest.Loopback, has
+	// the tty open too.
+	if rc, err := p.f.SyscallConn(); err == nil {
+		rc.Control(func(fd uintptr) { unix.IoctlSetInt(int(fd), unix.TIOCNXCL, 0) })
+	}
+	err := p.f.Close()
+	p.unlock()
+	return err
+}
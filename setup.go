@@ -0,0 +1,93 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// setup prepares a freshly probed modem: it reads the SIM's ICCID, enters
+// the configured PIN if the SIM asks for one, runs the configured init
+// commands and enables jamming indications. Failures are logged, they don't keep the modem from being
+// adopted. Returns the ICCID, empty if it could not be read.
+func (m *Manager) setup(node string, imei string) string {
+	p, err := m.openAT(context.Background(), node, imei)
+	if err != nil {
+		m.log.Warn("modem setup failed", "tty", node, "err", err)
+		return ""
+	}
+	defer p.Close()
+	return m.prepare(p)
+}
+
+// prepare runs the setup sequence on an open port.
+func (m *Manager) prepare(p *atPort) string {
+	m.mu.Lock()
+	pins := m.cfg.PINs
+	m.mu.Unlock()
+	init := m.initFor(p.node)
+
+	iccid := readIccid(p)
+	if pin, ok := pins[iccid]; ok {
+		if err := unlockSIM(p, pin); err != nil {
+			m.log.Warn("SIM unlock failed", "tty", p.node, "iccid", iccid, "err", err)
+		}
+	}
+	for _, cmd := range init {
+		if _, err := p.Command(cmd, commandTimeout); err != nil {
+			m.log.Warn("init command failed", "tty", p.node, "cmd", redact(cmd), "err", err)
+		}
+	}
+	m.enableJamming(p)
+	return iccid
+}
+
+// readIccid asks for the SIM's ICCID, first with AT+CCID, then by reading
+// EF_ICCID through AT+CRSM for modems without that command.
+func readIccid(p *atPort) string {
+	if resp, err := p.Command("AT+CCID", commandTimeout); err == nil {
+		if id := iccidDigits(resp); id != "" {
+			return id
+		}
+	}
+	resp, err := p.Command("AT+CRSM=176,12258,0,0,10", commandTimeout)
+	if err != nil {
+		return ""
+	}
+	// +CRSM: 144,0,"98940010..." - the file is BCD with swapped nibbles.
+	i := strings.LastIndex(resp, ",")
+	if i < 0 {
+		return ""
+	}
+	raw := []byte(strings.Trim(resp[i+1:], "\" \r\n"))
+	for j := 0; j+1 < len(raw); j += 2 {
+		raw[j], raw[j+1] = raw[j+1], raw[j]
+	}
+	return iccidDigits(string(raw))
+}
+
+// iccidDigits extracts the ICCID from a reply, dropping +CCID: prefixes,
+// quotes and the F padding.
+func iccidDigits(resp string) string {
+	if _, rest, ok := strings.Cut(resp, ":"); ok {
+		resp = rest
+	}
+	id := strings.TrimRight(strings.Trim(resp, "\" \r\n"), "Ff")
+	if !iccidRe.MatchString(id) {
+		return ""
+	}
+	return id
+}
+
+// unlockSIM enters pin if the SIM is waiting for it.
+func unlockSIM(p *atPort, pin string) error {
+	resp, err := p.Command("AT+CPIN?", commandTimeout)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(resp, "SIM PIN") {
+		return nil
+	}
+	_, err = p.Command(fmt.Sprintf("AT+CPIN=%q", pin), commandTimeout)
+	return err
+}
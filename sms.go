@@ -0,0 +1,84 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ausrasul/modem/atparse"
+)
+
+// Received text message.
+type SMS struct {
+	Sender string
+	Time   string // service centre time stamp, as sent by the modem
+	Text   string
+}
+
+// Read the unread text messages stored on the modem with the given IMEI.
+// The modem marks them as read.
+func (m *Manager) ReadSMS(imei string) ([]atparse.Message, error) {
+	if _, err := m.command(context.Background(), imei, "AT+CMGF=1"); err != nil {
+		return nil, err
+	}
+	resp, err := m.command(context.Background(), imei, `AT+CMGL="REC UNREAD"`)
+	if err != nil {
+		return nil, err
+	}
+	return atparse.ParseCMGL(resp)
+}
+
+// Delete the message stored at index on the modem with the given IMEI.
+func (m *Manager) DeleteSMS(imei string, index int) error {
+	_, err := m.command(context.Background(), imei, fmt.Sprintf("AT+CMGD=%d", index))
+	return err
+}
+
+// pollSMS sweeps every ready modem for unread messages until stop closes.
+func (m *Manager) pollSMS(stop chan struct{}) {
+	defer m.workers.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-m.clock.After(m.smsPoll):
+		}
+		for _, d := range m.List() {
+			if !m.isHeld(d.Tty) {
+				if err := m.sweepSMS(d); err != nil {
+					m.log.Warn("SMS poll failed", "imei", d.Imei, "err", err)
+				}
+			}
+		}
+	}
+}
+
+// sweepSMS publishes the unread messages of d, and deletes those that were
+// received if WithSMSDelete is set.
+func (m *Manager) sweepSMS(d Modem) error {
+	msgs, err := m.ReadSMS(d.Imei)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	del := m.smsDelete
+	m.mu.Unlock()
+	for _, msg := range msgs {
+		m.log.Info("SMS received", "imei", d.Imei, "sender", msg.Sender)
+		received := m.emitReceived(ModemEvent{
+			Type:  EventSMS,
+			Modem: d,
+			SMS:   SMS{Sender: msg.Sender, Time: msg.Time, Text: msg.Text},
+		})
+		if !del {
+			continue
+		}
+		if !received {
+			m.log.Warn("SMS kept on the modem, it was not received", "imei", d.Imei, "index", msg.Index)
+			continue
+		}
+		if err := m.DeleteSMS(d.Imei, msg.Index); err != nil {
+			m.log.Warn("SMS delete failed", "imei", d.Imei, "index", msg.Index, "err", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package modem_test
+
+import (
+	"testing"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/modemtest"
+)
+
+const imei = "490154203237518"
+
+// loopbackModem runs a manager whose only modem is the loopback l. The
+// returned function stops it.
+func loopbackModem(t *testing.T, l *modemtest.Loopback, opts ...modem.Option) (*modem.Manager, modem.Modem, func()) {
+	t.Helper()
+	modem.SetLockDir(t.TempDir())
+	_, tty := modem.FakeModem("1", imei, l.Path)
+	m := modem.New(append([]modem.Option{modem.WithBackend(modem.FakeBackend(tty))}, opts...)...)
+	m.AddFilter("12d1", "1001")
+	events := m.Events()
+	if err := m.Monitor(); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-events
+	m.Unsubscribe(events)
+	if ev.Type != modem.EventAdd {
+		t.Fatalf("first event %s, want add", ev.Type)
+	}
+	return m, ev.Modem, func() { m.StopMonitor() }
+}
+
+var listing = []modemtest.Exchange{
+	{Expect: "AT", Send: "\r\nOK\r\n"},
+	{Expect: "AT+CMGF=1", Send: "\r\nOK\r\n"},
+	{Expect: `AT+CMGL="REC UNREAD"`, Send: "\r\n+CMGL: 3,\"REC UNREAD\",\"+46701234567\",,\"26/10/14,15:30:00+08\"\r\nHello\r\n\r\nOK\r\n"},
+}
+
+func TestSweepSMSKeepsMessagesByDefault(t *testing.T) {
+	l, err := modemtest.NewLoopback(listing...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m, d, stop := loopbackModem(t, l)
+	defer stop()
+	events := m.Events()
+	defer m.Unsubscribe(events)
+
+	if err := m.SweepSMS(d); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-events
+	if ev.Type != modem.EventSMS || ev.SMS.Sender != "+46701234567" || ev.SMS.Text != "Hello" {
+		t.Errorf("event %s %+v, want the SMS", ev.Type, ev.SMS)
+	}
+	if err := l.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSweepSMSDeletesReceivedMessages(t *testing.T) {
+	l, err := modemtest.NewLoopback(append(listing, modemtest.Exchange{Expect: "AT+CMGD=3", Send: "\r\nOK\r\n"})...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m, d, stop := loopbackModem(t, l, modem.WithSMSDelete())
+	defer stop()
+	events := m.Events()
+	defer m.Unsubscribe(events)
+
+	if err := m.SweepSMS(d); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Type != modem.EventSMS {
+		t.Errorf("event %s, want sms", ev.Type)
+	}
+	if err := l.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSweepSMSKeepsMissedMessages(t *testing.T) {
+	l, err := modemtest.NewLoopback(listing...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m, d, stop := loopbackModem(t, l, modem.WithSMSDelete(), modem.WithEventBuffer(1))
+	defer stop()
+	full := m.Events()
+	defer m.Unsubscribe(full)
+	m.SetAlias(imei, "fills the buffer")
+
+	if err := m.SweepSMS(d); err != nil {
+		t.Fatal(err)
+	}
+	// No AT+CMGD: the script ends with the listing.
+	if err := l.Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Package smsrouter dispatches the text messages received by a
+modem.Manager to handlers chosen by sender or by the first word of the
+text.
+
+	r := smsrouter.New()
+	r.Keyword("STATUS", func(msg smsrouter.Message) {
+		msg.Reply("up")
+	})
+	r.Sender("+4670*", func(msg smsrouter.Message) {
+		log.Println("from the office:", msg.Text)
+	})
+	r.Default(func(msg smsrouter.Message) {
+		log.Println("unhandled SMS from", msg.Sender)
+	})
+	m := modem.New(modem.WithSMSPoll(30*time.Second), modem.WithPlugins(r))
+
+Routes are tried in the order they were added and the first match handles
+the message; messages no route matches go to the default handler, if any.
+Keywords are matched without regard to case. Sender patterns use the
+syntax of path.Match, so "+4670*" matches every number starting with
++4670. Handlers run one at a time, in the order messages arrive.
+*/
+package smsrouter
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/ausrasul/modem"
+)
+
+// Message is a received SMS being routed.
+type Message struct {
+	modem.SMS
+	Modem   modem.Modem // the modem that received it
+	Keyword string      // first word of the text, upper-cased
+	Args    []string    // words after the first
+
+	m *modem.Manager
+}
+
+// Reply sends text to the sender from the modem that received the message.
+func (msg Message) Reply(text string) error {
+	return msg.m.SendSMS(msg.Modem.Imei, msg.Sender, text)
+}
+
+// Handler handles a routed message.
+type Handler func(msg Message)
+
+type route struct {
+	keyword string // upper-cased, or empty for sender routes
+	sender  string
+	h       Handler
+}
+
+// Router is a modem.Plugin routing received SMS, see the package doc.
+type Router struct {
+	mu       sync.Mutex
+	routes   []route
+	fallback Handler
+
+	m      *modem.Manager
+	events <-chan modem.ModemEvent
+	done   chan struct{}
+}
+
+// New returns a router without routes.
+func New() *Router {
+	return &Router{}
+}
+
+// Keyword routes messages whose first word is keyword to h.
+func (r *Router) Keyword(keyword string, h Handler) {
+	r.add(route{keyword: strings.ToUpper(keyword), h: h})
+}
+
+// Sender routes messages whose sender matches pattern to h. It panics if
+// pattern is malformed.
+func (r *Router) Sender(pattern string, h Handler) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("smsrouter: invalid sender pattern " + pattern)
+	}
+	r.add(route{sender: pattern, h: h})
+}
+
+// Default handles the messages no route matches. Without it they are dropped.
+func (r *Router) Default(h Handler) {
+	r.mu.Lock()
+	r.fallback = h
+	r.mu.Unlock()
+}
+
+func (r *Router) add(rt route) {
+	r.mu.Lock()
+	r.routes = append(r.routes, rt)
+	r.mu.Unlock()
+}
+
+func (r *Router) Start(m *modem.Manager) error {
+	r.m = m
+	r.events = m.Events()
+	r.done = make(chan struct{})
+	go r.run()
+	return nil
+}
+
+func (r *Router) Stop() error {
+	r.m.Unsubscribe(r.events)
+	<-r.done
+	return nil
+}
+
+func (r *Router) run() {
+	defer close(r.done)
+	for ev := range r.events {
+		if ev.Type == modem.EventSMS {
+			r.Route(ev.Modem, ev.SMS)
+		}
+	}
+}
+
+// Route dispatches a message received by d as if it came from the manager.
+func (r *Router) Route(d modem.Modem, sms modem.SMS) {
+	msg := Message{SMS: sms, Modem: d, m: r.m}
+	if words := strings.Fields(sms.Text); len(words) > 0 {
+		msg.Keyword = strings.ToUpper(words[0])
+		msg.Args = words[1:]
+	}
+	if h := r.match(msg); h != nil {
+		h(msg)
+	}
+}
+
+// match returns the handler of the first matching route, or the default.
+func (r *Router) match(msg Message) Handler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rt := range r.routes {
+		if rt.keyword != "" {
+			if rt.keyword == msg.Keyword {
+				return rt.h
+			}
+			continue
+		}
+		if ok, _ := path.Match(rt.sender, msg.Sender); ok {
+			return rt.h
+		}
+	}
+	return r.fallback
+}
@@ -0,0 +1,72 @@
+package smsrouter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/smsrouter"
+)
+
+func TestRoute(t *testing.T) {
+	var got string
+	var msg smsrouter.Message
+	handler := func(name string) smsrouter.Handler {
+		return func(m smsrouter.Message) {
+			got, msg = name, m
+		}
+	}
+	r := smsrouter.New()
+	r.Keyword("status", handler("status"))
+	r.Sender("+4670*", handler("office"))
+	r.Keyword("STOP", handler("stop"))
+	r.Sender("+46[0-9][0-9]12", handler("pattern"))
+
+	tests := []struct {
+		sender  string
+		text    string
+		want    string
+		keyword string
+		args    []string
+	}{
+		{"+4612345", "Status", "status", "STATUS", []string{}},
+		{"+4612345", "  status  now please ", "status", "STATUS", []string{"now", "please"}},
+		{"+46701234", "status", "status", "STATUS", []string{}}, // added first
+		{"+46701234", "stop", "office", "STOP", []string{}},
+		{"+4612345", "stop all", "stop", "STOP", []string{"all"}},
+		{"+469912", "hello", "pattern", "HELLO", []string{}},
+		{"+46991234", "hello", "", "", nil},
+		{"+4612345", "statuses", "", "", nil},
+		{"+4612345", "", "", "", nil},
+	}
+	for _, tt := range tests {
+		got, msg = "", smsrouter.Message{}
+		d := modem.Modem{Imei: "490154203237518"}
+		r.Route(d, modem.SMS{Sender: tt.sender, Text: tt.text})
+		if got != tt.want {
+			t.Errorf("%s %q: routed to %q, want %q", tt.sender, tt.text, got, tt.want)
+			continue
+		}
+		if got == "" {
+			continue
+		}
+		if msg.Keyword != tt.keyword || !reflect.DeepEqual(msg.Args, tt.args) || msg.Modem.Imei != d.Imei || msg.Text != tt.text {
+			t.Errorf("%s %q: message %+v, want keyword %q, args %q", tt.sender, tt.text, msg, tt.keyword, tt.args)
+		}
+	}
+
+	r.Default(handler("default"))
+	r.Route(modem.Modem{}, modem.SMS{Sender: "+4612345", Text: "hello"})
+	if got != "default" {
+		t.Errorf("unmatched message routed to %q, want the default handler", got)
+	}
+}
+
+func TestInvalidSenderPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Sender did not panic on a malformed pattern")
+		}
+	}()
+	smsrouter.New().Sender("+46[", func(smsrouter.Message) {})
+}
@@ -0,0 +1,198 @@
+/*
+Package snmpagent exposes the modems of a modem.Manager to SNMP through an
+AgentX sub-agent of the host's master agent (net-snmp snmpd with
+"master agentx").
+
+	m := modem.New(modem.WithPlugins(snmpagent.Plugin(snmpagent.Config{})))
+
+The objects are described in MODEM-MIB.txt next to this file. Signal and
+registration are sampled every Interval rather than on each SNMP request,
+so a walk never waits on the modems. Rows are ordered by IMEI and their
+index changes when modems are plugged or unplugged; use modemImei to
+identify a modem.
+*/
+package snmpagent
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/atparse"
+	"github.com/posteo/go-agentx"
+	"github.com/posteo/go-agentx/pdu"
+	"github.com/posteo/go-agentx/value"
+)
+
+// DefaultBase is the root of MODEM-MIB, in the net-snmp experimental
+// arc. Replace it with an OID under your own enterprise number in
+// production.
+const DefaultBase = "1.3.6.1.4.1.8072.9999.9999.7160"
+
+// Config of the sub-agent. The zero value connects to snmpd's default
+// AgentX socket.
+type Config struct {
+	Network  string        // default unix
+	Address  string        // default /var/agentx/master
+	Base     string        // default DefaultBase
+	Interval time.Duration // signal and registration sampling, default 30s
+}
+
+// Columns of modemTable.
+const (
+	colIndex        = 1
+	colImei         = 2
+	colTty          = 3
+	colNet          = 4
+	colSignal       = 5 // dBm, 0 when unknown
+	colRegistration = 6 // +CREG stat
+)
+
+type agent struct {
+	cfg     Config
+	base    value.OID
+	m       *modem.Manager
+	client  *agentx.Client
+	session *agentx.Session
+	stop    chan struct{}
+	done    chan struct{}
+
+	mu   sync.RWMutex
+	list *agentx.ListHandler
+}
+
+// Plugin registers the MIB with the master agent while the manager is monitoring.
+func Plugin(cfg Config) modem.Plugin {
+	if cfg.Network == "" {
+		cfg.Network = "unix"
+	}
+	if cfg.Address == "" {
+		cfg.Address = "/var/agentx/master"
+	}
+	if cfg.Base == "" {
+		cfg.Base = DefaultBase
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second * 30
+	}
+	return &agent{cfg: cfg}
+}
+
+func (a *agent) Start(m *modem.Manager) error {
+	base, err := value.ParseOID(a.cfg.Base)
+	if err != nil {
+		return err
+	}
+	a.base = base
+	a.m = m
+	a.list = &agentx.ListHandler{}
+	a.client, err = agentx.Dial(a.cfg.Network, a.cfg.Address,
+		agentx.WithTimeout(time.Minute),
+		agentx.WithReconnectInterval(time.Second*5))
+	if err != nil {
+		return err
+	}
+	a.session, err = a.client.Session(base, "modem", a)
+	if err == nil {
+		err = a.session.Register(127, base)
+	}
+	if err != nil {
+		a.client.Close()
+		return err
+	}
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	go a.run()
+	return nil
+}
+
+func (a *agent) Stop() error {
+	close(a.stop)
+	<-a.done
+	a.session.Unregister(127, a.base)
+	a.session.Close()
+	return a.client.Close()
+}
+
+func (a *agent) run() {
+	defer close(a.done)
+	events := a.m.Events()
+	defer a.m.Unsubscribe(events)
+	a.sample()
+	t := time.NewTicker(a.cfg.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-t.C:
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			// Only plugging and unplugging change the table between samples.
+			if ev.Type != modem.EventAdd && ev.Type != modem.EventUpdate && ev.Type != modem.EventRemove {
+				continue
+			}
+		}
+		a.sample()
+	}
+}
+
+// sample queries every ready modem and replaces the served objects.
+func (a *agent) sample() {
+	var modems []modem.Modem
+	for _, d := range a.m.List() {
+		modems = append(modems, d)
+	}
+	sort.Slice(modems, func(i, j int) bool { return modems[i].Imei < modems[j].Imei })
+
+	l := &agentx.ListHandler{}
+	a.set(l, pdu.VariableTypeGauge32, uint32(len(modems)), 1)
+	for i, d := range modems {
+		row := uint32(i + 1)
+		reg := int32(atparse.UnknownState)
+		if r, err := a.m.Registration(d.Imei); err == nil {
+			reg = int32(r.Stat)
+		}
+		var dbm int32
+		if s, err := a.m.Signal(d.Imei); err == nil {
+			dbm = int32(s.DBm())
+		}
+		a.set(l, pdu.VariableTypeInteger, int32(row), 2, 1, colIndex, row)
+		a.set(l, pdu.VariableTypeOctetString, d.Imei, 2, 1, colImei, row)
+		a.set(l, pdu.VariableTypeOctetString, d.Tty, 2, 1, colTty, row)
+		a.set(l, pdu.VariableTypeOctetString, d.Net, 2, 1, colNet, row)
+		a.set(l, pdu.VariableTypeInteger, dbm, 2, 1, colSignal, row)
+		a.set(l, pdu.VariableTypeInteger, reg, 2, 1, colRegistration, row)
+	}
+	a.mu.Lock()
+	a.list = l
+	a.mu.Unlock()
+}
+
+// set adds the object at base.sub to l.
+func (a *agent) set(l *agentx.ListHandler, t pdu.VariableType, v any, sub ...uint32) {
+	oid := a.base.String()
+	for _, s := range sub {
+		oid += "." + strconv.FormatUint(uint64(s), 10)
+	}
+	item := l.Add(oid)
+	item.Type = t
+	item.Value = v
+}
+
+func (a *agent) Get(ctx context.Context, oid value.OID) (value.OID, pdu.VariableType, any, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.list.Get(ctx, oid)
+}
+
+func (a *agent) GetNext(ctx context.Context, from value.OID, includeFrom bool, to value.OID) (value.OID, pdu.VariableType, any, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.list.GetNext(ctx, from, includeFrom, to)
+}
@@ -0,0 +1,102 @@
+/*
+Package sqlitestore keeps the known modems of a modem.Manager in an SQLite
+table. It works with any database/sql SQLite driver, e.g. modernc.org/sqlite
+or github.com/mattn/go-sqlite3.
+
+	db, err := sql.Open("sqlite", "/var/lib/modem/modems.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := sqlitestore.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m := modem.New(modem.WithStore(s))
+
+The records make "missing since" reports a query away:
+
+	SELECT imei, alias, datetime(last_seen, 'unixepoch') FROM modems
+	WHERE last_seen < unixepoch('now', '-3 days');
+*/
+package sqlitestore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/ausrasul/modem"
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS modems (
+	imei       TEXT PRIMARY KEY,
+	iccid      TEXT NOT NULL,
+	vid        TEXT NOT NULL,
+	pid        TEXT NOT NULL,
+	alias      TEXT NOT NULL,
+	config     TEXT,
+	first_seen INTEGER NOT NULL,
+	last_seen  INTEGER NOT NULL
+)`
+
+// Store is a modem.Store backed by the modems table of an SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// New creates the modems table in db if needed. The caller closes db.
+func New(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Load() ([]modem.Record, error) {
+	rows, err := s.db.Query(`SELECT imei, iccid, vid, pid, alias, config, first_seen, last_seen FROM modems`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []modem.Record
+	for rows.Next() {
+		var r modem.Record
+		var config sql.NullString
+		var first, last int64
+		if err := rows.Scan(&r.Imei, &r.Iccid, &r.Vid, &r.Pid, &r.Alias, &config, &first, &last); err != nil {
+			return nil, err
+		}
+		if config.Valid {
+			r.Config = &modem.DeviceConfig{}
+			if err := json.Unmarshal([]byte(config.String), r.Config); err != nil {
+				return nil, err
+			}
+		}
+		r.FirstSeen, r.LastSeen = time.Unix(first, 0), time.Unix(last, 0)
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+func (s *Store) Save(r modem.Record) error {
+	var config sql.NullString
+	if r.Config != nil {
+		b, err := json.Marshal(r.Config)
+		if err != nil {
+			return err
+		}
+		config = sql.NullString{String: string(b), Valid: true}
+	}
+	_, err := s.db.Exec(`INSERT INTO modems (imei, iccid, vid, pid, alias, config, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (imei) DO UPDATE SET iccid = excluded.iccid, vid = excluded.vid,
+		pid = excluded.pid, alias = excluded.alias, config = excluded.config,
+		first_seen = excluded.first_seen, last_seen = excluded.last_seen`,
+		r.Imei, r.Iccid, r.Vid, r.Pid, r.Alias, config, r.FirstSeen.Unix(), r.LastSeen.Unix())
+	return err
+}
+
+func (s *Store) Delete(imei string) error {
+	_, err := s.db.Exec(`DELETE FROM modems WHERE imei = ?`, imei)
+	return err
+}
@@ -0,0 +1,136 @@
+package modem
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// State of a modem in the manager.
+type State int
+
+const (
+	StateProbing State = iota // found, its ports are being probed
+	StateReady                // its command port answered, see List
+	StateFailed               // no port answered, probing gave up
+)
+
+func (s State) String() string {
+	switch s {
+	case StateProbing:
+		return "probing"
+	case StateReady:
+		return "ready"
+	case StateFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// Retry a failed probe after first, doubling the delay each time, for up
+// to attempts probes in all. Its other ports are then tried once each. A
+// modem none of whose ports answered then
+// goes to StateFailed with an EventGaveUp, and is not probed again until
+// it is plugged in anew. Defaults to 5 seconds and 5 attempts.
+func WithProbeBackoff(first time.Duration, attempts int) Option {
+	return func(m *Manager) {
+		if attempts < 1 {
+			attempts = 1
+		}
+		m.backoff = first
+		m.attempts = attempts
+	}
+}
+
+var errStopped = errors.New("Monitor stopped")
+
+// probeImei queries the IMEI of a port, retrying while the modem has no
+// command port yet. The probe slot is given up while waiting to retry.
+// Returns errStopped if the monitor stopped.
+func (m *Manager) probeImei(stop chan struct{}, key string, node string) (string, error) {
+	delay := m.backoff
+	for attempt := 1; ; attempt++ {
+		imei, err := m.getImei(node)
+		if err == nil || attempt >= m.attempts || errors.Is(err, ErrPortHeld) {
+			return imei, err
+		}
+		m.mu.Lock()
+		d, ok := m.devices[key]
+		m.mu.Unlock()
+		if !ok || d.State == StateReady {
+			return imei, err
+		}
+		m.log.Debug("IMEI probe failed, retrying", "usb", key, "tty", node, "attempt", attempt, "delay", delay, "err", err)
+		<-m.probeSlots
+		select {
+		case <-m.clock.After(delay):
+		case <-stop:
+			// The caller releases a slot. The ones held by other probes
+			// are released as they see stop too.
+			m.probeSlots <- struct{}{}
+			return "", errStopped
+		}
+		select {
+		case m.probeSlots <- struct{}{}:
+		case <-stop:
+			m.probeSlots <- struct{}{}
+			return "", errStopped
+		}
+		delay *= 2
+	}
+}
+
+// fallback tries the other ports of a modem whose command port didn't
+// answer, data ports first and then the ones not classified yet, as some
+// firmware revisions move the AT interface. Diagnostics ports are not
+// tried. It returns the first port that gave an IMEI, as the command
+// port. Nothing is tried once another probe made the modem ready.
+func (m *Manager) fallback(key string, failed string) (Port, string, bool) {
+	m.mu.Lock()
+	d, ok := m.devices[key]
+	pending := m.pending[key]
+	m.mu.Unlock()
+	if !ok || d.State != StateProbing {
+		return Port{}, "", false
+	}
+	ports := make([]Port, 0, len(d.Ports)+len(pending))
+	for _, p := range d.Ports {
+		if p.Node != failed && p.Role == PortData {
+			ports = append(ports, p)
+		}
+	}
+	ports = append(ports, pending...)
+	for _, p := range ports {
+		if m.isHeld(p.Node) {
+			continue
+		}
+		m.log.Debug("trying another port", "usb", key, "tty", p.Node, "role", p.Role)
+		if imei, err := m.getImei(p.Node); err == nil {
+			m.mu.Lock()
+			m.pending[key] = slices.DeleteFunc(m.pending[key], func(q Port) bool { return q.Node == p.Node })
+			m.mu.Unlock()
+			p.Role = PortCommand
+			return p, imei, true
+		}
+	}
+	return Port{}, "", false
+}
+
+// giveUp marks a modem whose probes all failed as failed, unless another
+// of its ports answered meanwhile. The event names the command port
+// candidate that failed as the modem's Tty.
+func (m *Manager) giveUp(key string, node string) {
+	m.mu.Lock()
+	d, ok := m.devices[key]
+	ok = ok && d.State == StateProbing
+	if ok {
+		d.State = StateFailed
+		m.devices[key] = d
+	}
+	m.mu.Unlock()
+	if ok {
+		m.log.Warn("modem probing gave up", "usb", key, "tty", node)
+		d.Tty = node
+		m.emit(ModemEvent{Type: EventGaveUp, Modem: d})
+	}
+}
@@ -0,0 +1,108 @@
+package modem_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/ausrasul/modem/modemtest"
+	"golang.org/x/sys/unix"
+)
+
+func TestProbeBackoff(t *testing.T) {
+	failed := modemtest.Exchange{Expect: "AT+CGSN", Send: "\r\nERROR\r\n"}
+	answered := modemtest.Exchange{Expect: "AT+CGSN", Send: "\r\n" + imei + "\r\n\r\nOK\r\n"}
+	tests := []struct {
+		name     string
+		attempts int
+		script   []modemtest.Exchange // after baud rate detection
+		imei     string
+		waited   time.Duration
+	}{
+		{"first attempt", 5, []modemtest.Exchange{answered}, imei, 0},
+		{"third attempt", 5, []modemtest.Exchange{failed, failed, answered}, imei, 15 * time.Second},
+		{"gives up", 3, []modemtest.Exchange{failed, failed, failed}, "", 15 * time.Second},
+		{"single attempt", 1, []modemtest.Exchange{failed}, "", 0},
+	}
+	modem.SetLockDir(t.TempDir())
+	for _, tt := range tests {
+		l, err := modemtest.NewLoopback(append([]modemtest.Exchange{{Expect: "AT", Send: "\r\nOK\r\n"}}, tt.script...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock := modemtest.NewClock(time.Unix(0, 0))
+		m := modem.New(modem.WithProbeBackoff(5*time.Second, tt.attempts))
+		m.SetClock(clock)
+
+		got, err := m.ProbeImei(l.Path)
+		if got != tt.imei || (err == nil) != (tt.imei != "") {
+			t.Errorf("%s: ProbeImei() = %q, %v, want %q", tt.name, got, err, tt.imei)
+		}
+		if waited := clock.Now().Sub(time.Unix(0, 0)); waited != tt.waited {
+			t.Errorf("%s: waited %v, want %v", tt.name, waited, tt.waited)
+		}
+		if err := l.Err(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		l.Close()
+	}
+}
+
+func TestProbeTimeoutOnFakeClock(t *testing.T) {
+	modem.SetLockDir(t.TempDir())
+	l, err := modemtest.NewLoopback(modemtest.Exchange{Expect: "AT"}, modemtest.Exchange{Expect: "AT+CGSN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	clock := modemtest.NewClock(time.Unix(0, 0))
+	m := modem.New(modem.WithBaudRates(115200), modem.WithProbeBackoff(time.Second, 1))
+	m.SetClock(clock)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.ProbeImei(l.Path)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, modem.ErrTimeout) {
+			t.Errorf("ProbeImei() = %v, want %v", err, modem.ErrTimeout)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ProbeImei of a silent port did not time out")
+	}
+	if waited := clock.Now().Sub(time.Unix(0, 0)); waited < 5*time.Second {
+		t.Errorf("waited %v on the clock, want the command timeout", waited)
+	}
+}
+
+func TestDetectionDoesNotReopen(t *testing.T) {
+	modem.SetLockDir(t.TempDir())
+	l, err := modemtest.NewLoopback(modemtest.Exchange{Expect: "AT+CGSN", Send: "\r\n" + imei + "\r\n\r\nOK\r\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	m := modem.New(modem.WithBaudRates(115200), modem.WithProbeBackoff(time.Second, 1))
+	var mu sync.Mutex
+	failed := false
+	m.SetFaults(modem.Faults{SerialError: func(port, op string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if op != modem.OpWrite || failed {
+			return nil
+		}
+		failed = true
+		return unix.EIO
+	}})
+
+	if got, err := m.ProbeImei(l.Path); got != imei || err != nil {
+		t.Errorf("ProbeImei() = %q, %v, want %q", got, err, imei)
+	}
+	if err := l.Err(); err != nil {
+		t.Error(err)
+	}
+}
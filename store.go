@@ -0,0 +1,144 @@
+package modem
+
+import (
+	"sort"
+	"time"
+)
+
+// Record is what a Store keeps about a modem that was ready at least once.
+type Record struct {
+	Imei      string        `json:"imei"`
+	Iccid     string        `json:"iccid"`
+	Vid       string        `json:"vid"`
+	Pid       string        `json:"pid"`
+	Alias     string        `json:"alias"`            // set for the IMEI, see SetAlias
+	Config    *DeviceConfig `json:"config,omitempty"` // override in use when last seen
+	FirstSeen time.Time     `json:"first_seen"`
+	LastSeen  time.Time     `json:"last_seen"`
+	Present   bool          `json:"-"` // only set by Known
+}
+
+// Store persists the known modems across restarts, see WithStore. The
+// boltstore and sqlitestore packages implement it.
+type Store interface {
+	Load() ([]Record, error)
+	Save(r Record) error
+	Delete(imei string) error
+}
+
+// Keep the known modems in s. Monitor loads them before it starts, and
+// aliases set for an IMEI with SetAlias are restored from them unless the
+// configuration has one.
+func WithStore(s Store) Option {
+	return func(m *Manager) {
+		m.db = s
+	}
+}
+
+// load reads the store once, the first time the monitor starts.
+func (m *Manager) load() error {
+	if m.db == nil || m.known != nil {
+		return nil
+	}
+	records, err := m.db.Load()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.known = make(map[string]Record, len(records))
+	for _, r := range records {
+		m.known[r.Imei] = r
+	}
+	m.restoreAliasesLocked()
+	return nil
+}
+
+// restoreAliasesLocked adds the aliases of the stored modems that the
+// configuration has none for. m.mu must be held.
+func (m *Manager) restoreAliasesLocked() {
+	aliases := make(map[string]string, len(m.cfg.Aliases)+len(m.known))
+	for k, v := range m.cfg.Aliases {
+		aliases[k] = v
+	}
+	for imei, r := range m.known {
+		if _, ok := aliases[imei]; !ok && r.Alias != "" {
+			aliases[imei] = r.Alias
+		}
+	}
+	m.cfg.Aliases = aliases
+}
+
+// remember updates the record of a modem that was published. Removed
+// modems keep what was recorded while they were plugged in.
+func (m *Manager) remember(action string, d Modem) {
+	if m.db == nil || d.Imei == "" {
+		return
+	}
+	now := m.clock.Now()
+	m.mu.Lock()
+	if m.known == nil {
+		m.known = make(map[string]Record)
+	}
+	r, ok := m.known[d.Imei]
+	if !ok && d.State != StateReady {
+		m.mu.Unlock()
+		return
+	}
+	if !ok {
+		r = Record{Imei: d.Imei, FirstSeen: now}
+	}
+	if action != "remove" {
+		r.Iccid, r.Vid, r.Pid = d.Iccid, d.Vid, d.Pid
+		r.Alias = m.cfg.Aliases[d.Imei]
+		r.Config = nil
+		if c, ok := m.overrides[d.Tty]; ok {
+			r.Config = &c
+		}
+	}
+	r.LastSeen = now
+	m.known[d.Imei] = r
+	m.mu.Unlock()
+	if err := m.db.Save(r); err != nil {
+		m.log.Warn("modem not saved", "imei", d.Imei, "err", err)
+	}
+}
+
+// Returns every modem in the store, sorted by IMEI. Modems ready now, or
+// unplugged within the replug grace period, are Present and last seen now.
+func (m *Manager) Known() []Record {
+	now := m.clock.Now()
+	m.mu.Lock()
+	present := make(map[string]bool)
+	for _, d := range m.devices {
+		if d.State == StateReady {
+			present[d.Imei] = true
+		}
+	}
+	for imei := range m.departed {
+		present[imei] = true
+	}
+	records := make([]Record, 0, len(m.known))
+	for _, r := range m.known {
+		if present[r.Imei] {
+			r.Present = true
+			r.LastSeen = now
+		}
+		records = append(records, r)
+	}
+	m.mu.Unlock()
+	sort.Slice(records, func(i, j int) bool { return records[i].Imei < records[j].Imei })
+	return records
+}
+
+// Remove the modem with the given IMEI from the store. It is added back if
+// it is ready again.
+func (m *Manager) Forget(imei string) error {
+	if m.db == nil {
+		return nil
+	}
+	m.mu.Lock()
+	delete(m.known, imei)
+	m.mu.Unlock()
+	return m.db.Delete(imei)
+}
@@ -0,0 +1,82 @@
+/*
+Package systemd reports the state of a modem.Manager to systemd.
+
+	m := modem.New(modem.WithPlugins(systemd.Plugin()))
+
+The plugin sends READY=1 once the monitor goroutine runs, for services of
+Type=notify. If the unit sets WatchdogSec, it pets the watchdog at half
+that interval for as long as the monitor is healthy, so a wedged monitor
+loop gets the service restarted.
+*/
+package systemd
+
+import (
+	"time"
+
+	"github.com/ausrasul/modem"
+	"github.com/coreos/go-systemd/v22/daemon"
+)
+
+// How often the plugin checks whether the monitor came up.
+const readyPoll = time.Millisecond * 100
+
+type notifier struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Plugin notifies systemd of readiness and pets its watchdog.
+func Plugin() modem.Plugin {
+	return &notifier{}
+}
+
+func (n *notifier) Start(m *modem.Manager) error {
+	interval, err := daemon.SdWatchdogEnabled(false)
+	if err != nil {
+		return err
+	}
+	n.stop = make(chan struct{})
+	n.done = make(chan struct{})
+	go n.run(m, interval)
+	return nil
+}
+
+func (n *notifier) Stop() error {
+	close(n.stop)
+	<-n.done
+	_, err := daemon.SdNotify(false, daemon.SdNotifyStopping)
+	return err
+}
+
+func (n *notifier) run(m *modem.Manager, interval time.Duration) {
+	defer close(n.done)
+	// Until the watchdog is known, consider the monitor stuck after a minute.
+	maxBusy := time.Minute
+	if interval > 0 {
+		maxBusy = interval
+	}
+	for !m.Healthy(maxBusy) {
+		select {
+		case <-n.stop:
+			return
+		case <-time.After(readyPoll):
+		}
+	}
+	daemon.SdNotify(false, daemon.SdNotifyReady)
+	if interval == 0 {
+		<-n.stop
+		return
+	}
+	t := time.NewTicker(interval / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-t.C:
+			if m.Healthy(maxBusy) {
+				daemon.SdNotify(false, daemon.SdNotifyWatchdog)
+			}
+		}
+	}
+}
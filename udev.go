@@ -0,0 +1,87 @@
+package modem
+
+import (
+	"time"
+
+	"github.com/ausrasul/udev"
+)
+
+// udevBackend reads tty and net devices from udev.
+type udevBackend struct {
+	m *Manager
+}
+
+func (b udevBackend) Run(stop <-chan struct{}, handle func(Device)) error {
+	u := udev.NewUdev()
+	defer u.Unref()
+
+	e := u.NewEnumerate()
+	defer e.Unref()
+
+	mon := udev.NewMonitorFromNetlink(u, "udev")
+	defer mon.Unref()
+
+	mon.AddFilter("tty", "")
+	mon.AddFilter("net", "")
+	mon.AddFilter("usbmisc", "")
+	mon.AddFilter("usb", "usb_device")
+
+	err := mon.EnableReceiving()
+	if err != nil {
+		return err
+	}
+
+	e.AddMatchSubsystem("tty")
+	e.AddMatchSubsystem("net")
+	e.AddMatchSubsystem("usbmisc")
+	e.ScanDevices()
+
+	for device := e.First(); !device.IsNil(); device = device.Next() {
+		handle(udevDevice{u.DeviceFromSysPath(device.Name())})
+	}
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+			d := mon.ReceiveDevice()
+			if !d.IsNil() {
+				handle(udevDevice{d})
+			} else {
+				// Real time: the kernel is polled, and a fake Clock
+				// returning at once would make this spin.
+				select {
+				case <-stop:
+					return nil
+				case <-time.After(time.Second):
+				}
+			}
+		}
+	}
+}
+
+type udevDevice struct {
+	d *udev.Device
+}
+
+func (d udevDevice) Action() string          { return d.d.Action() }
+func (d udevDevice) Subsystem() string       { return d.d.Subsystem() }
+func (d udevDevice) SysName() string         { return d.d.SysName() }
+func (d udevDevice) DevNode() string         { return d.d.DevNode() }
+func (d udevDevice) Attr(name string) string { return d.d.SysAttrValue(name) }
+
+func (d udevDevice) Property(name string) string { return d.d.PropertyValue(name) }
+
+// The interface is the parent of ttyACM and cdc-wdm devices, and the
+// grandparent of ttyUSB ones, below their usb-serial port.
+func (d udevDevice) InterfaceAttr(name string) string {
+	return d.d.ParentWithSubsystemDevType("usb", "usb_interface").SysAttrValue(name)
+}
+
+func (d udevDevice) USBDevice() Device {
+	usb := d.d.ParentWithSubsystemDevType("usb", "usb_device")
+	if usb.IsNil() {
+		return nil
+	}
+	return udevDevice{usb}
+}
@@ -0,0 +1,207 @@
+/*
+Package webhook POSTs modem events and received SMS from a modem.Manager
+as JSON to HTTP endpoints.
+
+	m := modem.New(modem.WithPlugins(webhook.Plugin(webhook.Config{
+		URLs:   []string{"https://example.com/hooks/modem"},
+		Secret: os.Getenv("WEBHOOK_SECRET"),
+	})))
+
+Each request carries an X-Modem-Event header with the event type and an
+X-Modem-Timestamp header with the Unix time of the delivery. With a
+Secret configured, X-Modem-Signature is "sha256=" followed by the hex
+HMAC-SHA256 of the timestamp, a dot and the body, so receivers can check
+both origin and freshness.
+
+Failed deliveries (network errors and 5xx responses) are retried with
+exponential backoff. Each endpoint has its own queue; when an endpoint
+falls too far behind, new events for it are dropped. Stopping the plugin
+abandons the deliveries still queued or waiting to be retried.
+*/
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ausrasul/modem"
+)
+
+// Events waiting for delivery per endpoint.
+const queueSize = 64
+
+// Retries of a Config that makes a single attempt per event.
+const NoRetries = -1
+
+// Config of the dispatcher. Only URLs is required.
+type Config struct {
+	URLs    []string
+	Secret  string        // HMAC key, no signature when empty
+	Retries int           // retries after the first attempt, default 3, NoRetries for none
+	Backoff time.Duration // delay before the first retry, doubled for each retry, default 1s
+	Client  *http.Client  // default has a 10 second timeout
+}
+
+// Body of each request.
+type Payload struct {
+	Type  string `json:"type"`
+	Seq   uint64 `json:"seq"` // see modem.ModemEvent, repeated by retries
+	Time  string `json:"time"`
+	Imei  string `json:"imei"`
+	Iccid string `json:"iccid,omitempty"`
+	Tty   string `json:"tty"`
+	Net   string `json:"net"`
+	SMS   *SMS   `json:"sms,omitempty"`
+}
+
+// Received message, set for sms events.
+type SMS struct {
+	Sender string `json:"sender"`
+	Time   string `json:"time"`
+	Text   string `json:"text"`
+}
+
+type dispatcher struct {
+	cfg    Config
+	m      *modem.Manager
+	events <-chan modem.ModemEvent
+	queues []chan Payload
+	ctx    context.Context // cancelled by Stop
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Plugin delivers the manager's events to the configured endpoints.
+func Plugin(cfg Config) modem.Plugin {
+	switch {
+	case cfg.Retries == 0:
+		cfg.Retries = 3
+	case cfg.Retries < 0:
+		cfg.Retries = 0
+	}
+	if cfg.Backoff == 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &dispatcher{cfg: cfg}
+}
+
+func (d *dispatcher) Start(m *modem.Manager) error {
+	d.m = m
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.queues = nil
+	for _, url := range d.cfg.URLs {
+		q := make(chan Payload, queueSize)
+		d.queues = append(d.queues, q)
+		d.wg.Add(1)
+		go d.deliver(url, q)
+	}
+	d.events = m.Events()
+	d.wg.Add(1)
+	go d.fanout()
+	return nil
+}
+
+func (d *dispatcher) Stop() error {
+	d.cancel()
+	d.m.Unsubscribe(d.events)
+	d.wg.Wait()
+	return nil
+}
+
+func (d *dispatcher) fanout() {
+	defer d.wg.Done()
+	defer func() {
+		for _, q := range d.queues {
+			close(q)
+		}
+	}()
+	for ev := range d.events {
+		p := Payload{
+			Type:  ev.Type.String(),
+			Seq:   ev.Seq,
+			Time:  time.Now().UTC().Format(time.RFC3339),
+			Imei:  ev.Modem.Imei,
+			Iccid: ev.Modem.Iccid,
+			Tty:   ev.Modem.Tty,
+			Net:   ev.Modem.Net,
+		}
+		if ev.Type == modem.EventSMS {
+			p.SMS = &SMS{Sender: ev.SMS.Sender, Time: ev.SMS.Time, Text: ev.SMS.Text}
+		}
+		for _, q := range d.queues {
+			select {
+			case q <- p:
+			default:
+			}
+		}
+	}
+}
+
+func (d *dispatcher) deliver(url string, q <-chan Payload) {
+	defer d.wg.Done()
+	for p := range q {
+		body, err := json.Marshal(p)
+		if err != nil || d.ctx.Err() != nil {
+			continue
+		}
+		backoff := d.cfg.Backoff
+		for attempt := 0; attempt <= d.cfg.Retries; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-time.After(backoff):
+				case <-d.ctx.Done():
+				}
+				backoff *= 2
+			}
+			if d.ctx.Err() != nil || d.post(url, p.Type, body) == nil {
+				break
+			}
+		}
+	}
+}
+
+// post sends one request; only failures worth retrying return an error.
+func (d *dispatcher) post(url, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Modem-Event", event)
+	req.Header.Set("X-Modem-Timestamp", ts)
+	if d.cfg.Secret != "" {
+		req.Header.Set("X-Modem-Signature", "sha256="+Sign(d.cfg.Secret, ts, body))
+	}
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of timestamp + "." + body, as sent in
+// X-Modem-Signature. Receivers compute it to verify a request.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
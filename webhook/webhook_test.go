@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ausrasul/modem"
+)
+
+// failing returns an endpoint answering 503 and the count of its requests.
+func failing(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		retries int
+		want    int32 // requests
+	}{
+		{NoRetries, 1},
+		{0, 4},
+		{1, 2},
+	}
+	for _, tt := range tests {
+		srv, n := failing(t)
+		d := Plugin(Config{URLs: []string{srv.URL}, Retries: tt.retries, Backoff: time.Millisecond}).(*dispatcher)
+		if err := d.Start(modem.New()); err != nil {
+			t.Fatal(err)
+		}
+		d.queues[0] <- Payload{Type: "add"}
+		deadline := time.Now().Add(5 * time.Second)
+		for n.Load() < tt.want && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if got := n.Load(); got != tt.want {
+			t.Errorf("Retries %d: %d requests, want %d", tt.retries, got, tt.want)
+		}
+		d.Stop()
+	}
+}
+
+func TestStopAbandonsRetries(t *testing.T) {
+	srv, n := failing(t)
+	d := Plugin(Config{URLs: []string{srv.URL}, Backoff: time.Hour}).(*dispatcher)
+	if err := d.Start(modem.New()); err != nil {
+		t.Fatal(err)
+	}
+	d.queues[0] <- Payload{Type: "add"}
+	d.queues[0] <- Payload{Type: "remove"}
+	for n.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		d.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop waited for the retry backoff")
+	}
+	if got := n.Load(); got != 1 {
+		t.Errorf("%d requests, want 1", got)
+	}
+}